@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/ryudokung/Project-0/backend/internal/auth"
@@ -31,6 +33,17 @@ func main() {
 	}
 	defer db.Close()
 
+	// Balance config: validated at startup, reloaded on file change or via the admin endpoint
+	balancePath := os.Getenv("BALANCE_CONFIG")
+	if balancePath == "" {
+		balancePath = "configs/game_balance.yaml"
+	}
+	balanceProvider, err := combat.NewBalanceProvider(balancePath)
+	if err != nil {
+		log.Fatal("Failed to load balance config:", err)
+	}
+	go balanceProvider.Watch(context.Background(), 10*time.Second)
+
 	// Initialize Blueprints
 	blueprints := game.NewBlueprintRegistry()
 	if err := blueprints.LoadNodes("blueprints/nodes.yaml"); err != nil {
@@ -42,6 +55,12 @@ func main() {
 	if err := blueprints.LoadExpeditions("blueprints/expeditions.yaml"); err != nil {
 		log.Printf("Warning: Failed to load expedition blueprints: %v", err)
 	}
+	if err := blueprints.LoadLoot("blueprints/loot.yaml"); err != nil {
+		log.Printf("Warning: Failed to load loot tables: %v", err)
+	}
+	if err := blueprints.LoadHazards("blueprints/hazards.yaml"); err != nil {
+		log.Printf("Warning: Failed to load hazards: %v", err)
+	}
 
 	// Initialize Game/Pilot Module
 	gameRepo := game.NewRepository(db)
@@ -61,15 +80,19 @@ func main() {
 	vehicleUseCase := vehicle.NewUseCase(vehicleRepo)
 	vehicleHandler := vehicle.NewHandler(vehicleUseCase)
 
-	// Initialize Combat Module
-	combatEngine := combat.NewEngine()
-	combatService := combat.NewService(combatEngine)
-	combatHandler := combat.NewHandler(combatService, vehicleRepo, gameRepo)
-
 	// Initialize Exploration Module
 	explorationRepo := exploration.NewRepository(db)
 	explorationService := exploration.NewService(explorationRepo, vehicleUseCase, gameRepo, blueprints)
 	explorationHandler := exploration.NewHandler(explorationService)
+	explorationAdminHandler := exploration.NewAdminHandler(explorationService, os.Getenv("ADMIN_TOKEN"))
+
+	// Initialize Combat Module
+	combatEngine := combat.NewEngine()
+	combatService := combat.NewService(combatEngine)
+	combatRepo := combat.NewRepository(db)
+	combatHandler := combat.NewHandler(combatService, combatRepo, vehicleRepo, vehicleUseCase, gameRepo, explorationService)
+	go combat.RunLogJanitor(context.Background(), combatRepo, time.Hour)
+	balanceHandler := combat.NewBalanceHandler(balanceProvider, os.Getenv("ADMIN_TOKEN"))
 
 	// Initialize Game Handler
 	gameHandler := game.NewHandler(gameUseCase, gameRepo)
@@ -89,6 +112,8 @@ func main() {
 	mux.HandleFunc("/api/v1/auth/login", authHandler.Login)
 	mux.HandleFunc("/api/v1/auth/signup", authHandler.Signup)
 	mux.HandleFunc("/api/v1/exploration/universe-map", explorationHandler.GetUniverseMap)
+	mux.HandleFunc("/api/v1/admin/balance/reload", balanceHandler.Reload) // Guarded by X-Admin-Token
+	mux.HandleFunc("/api/v1/admin/expeditions/regenerate", explorationAdminHandler.RegenerateTimeline) // Guarded by X-Admin-Token
 	
 	// Protected Routes Middleware
 	authMiddleware := auth.Middleware(authUseCase)
@@ -110,12 +135,21 @@ func main() {
 	mux.Handle("/api/v1/items/repair", authMiddleware(http.HandlerFunc(vehicleHandler.RepairItem)))
 
 	mux.Handle("/api/v1/combat/attack", authMiddleware(http.HandlerFunc(combatHandler.SimulateAttack)))
+	mux.Handle("/api/v1/combat/sessions/start", authMiddleware(http.HandlerFunc(combatHandler.StartBattle)))
+	mux.Handle("/api/v1/combat/sessions/act", authMiddleware(http.HandlerFunc(combatHandler.Act)))
+	mux.Handle("/api/v1/combat/sessions/resonance", authMiddleware(http.HandlerFunc(combatHandler.UseResonance)))
+	mux.Handle("/api/v1/combat/sessions", authMiddleware(http.HandlerFunc(combatHandler.GetBattleState)))
+	mux.Handle("/api/v1/combat/logs", authMiddleware(http.HandlerFunc(combatHandler.ListBattleLogs)))
+	mux.Handle("/api/v1/combat/logs/save", authMiddleware(http.HandlerFunc(combatHandler.SaveBattleLog)))
+	mux.Handle("/api/v1/combat/balance", authMiddleware(http.HandlerFunc(balanceHandler.GetBalance)))
 	mux.Handle("/api/v1/gacha/pull", authMiddleware(http.HandlerFunc(gachaHandler.Pull)))
 	mux.Handle("/api/v1/exploration/start", authMiddleware(http.HandlerFunc(explorationHandler.StartExploration)))
 	mux.Handle("/api/v1/exploration/timeline", authMiddleware(http.HandlerFunc(explorationHandler.GetTimeline)))
 	mux.Handle("/api/v1/exploration/resolve", authMiddleware(http.HandlerFunc(explorationHandler.ResolveChoice)))
 	mux.Handle("/api/v1/exploration/resolve-node", authMiddleware(http.HandlerFunc(explorationHandler.ResolveNode)))
 	mux.Handle("/api/v1/exploration/advance", authMiddleware(http.HandlerFunc(explorationHandler.AdvanceTimeline)))
+	mux.Handle("/api/v1/exploration/complete", authMiddleware(http.HandlerFunc(explorationHandler.CompleteExpedition)))
+	mux.Handle("/api/v1/exploration/abandon", authMiddleware(http.HandlerFunc(explorationHandler.AbandonExpedition)))
 	mux.Handle("/api/v1/game/pilot-stats", authMiddleware(http.HandlerFunc(gameHandler.GetPilotStats)))
 	mux.Handle("/api/v1/game/research/unlock", authMiddleware(http.HandlerFunc(gameHandler.UnlockResearch)))
 
@@ -123,7 +157,7 @@ func main() {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Admin-Token")
 
 		if r.Method == "OPTIONS" {
 			return
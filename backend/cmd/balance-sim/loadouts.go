@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/combat"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
+	"gopkg.in/yaml.v3"
+)
+
+// Loadout is a player build to simulate: a vehicle (nil = Pilot Only), its equipped items and the pilot
+type Loadout struct {
+	Name    string `yaml:"name"`
+	Vehicle *struct {
+		Class   string `yaml:"class"`
+		HP      int    `yaml:"hp"`
+		Attack  int    `yaml:"attack"`
+		Defense int    `yaml:"defense"`
+		Speed   int    `yaml:"speed"`
+	} `yaml:"vehicle"`
+	Pilot struct {
+		SyncLevel      int            `yaml:"sync_level"`
+		ResonanceLevel int            `yaml:"resonance_level"`
+		Attributes     map[string]int `yaml:"attributes"`
+		Research       []string       `yaml:"research"` // Unlocked Engineering Matrix nodes
+	} `yaml:"pilot"`
+	Items []LoadoutItem `yaml:"items"`
+}
+
+type LoadoutItem struct {
+	Name             string `yaml:"name"`
+	DamageType       string `yaml:"damage_type"` // Non-empty makes the item a weapon
+	Attack           int    `yaml:"attack"`
+	EnergyConsume    int    `yaml:"energy_consume"`
+	Cooldown         *int   `yaml:"cooldown"` // Overrides the balance default for the damage type
+	BonusHP          int    `yaml:"bonus_hp"`
+	BonusAttack      int    `yaml:"bonus_attack"`
+	BonusDefense     int    `yaml:"bonus_defense"`
+	ShieldGeneration int    `yaml:"shield_generation"`
+}
+
+func loadLoadouts(path string) ([]Loadout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Loadouts []Loadout `yaml:"loadouts"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if len(config.Loadouts) == 0 {
+		return nil, fmt.Errorf("no loadouts in %s", path)
+	}
+	return config.Loadouts, nil
+}
+
+// Stats builds the loadout's combat stats through the same mapping the API uses.
+// It must be called after the balance config under test is active.
+func (l Loadout) Stats(service *combat.Service) combat.UnitStats {
+	pilot := &game.PilotStats{
+		SyncLevel:           l.Pilot.SyncLevel,
+		ResonanceLevel:      l.Pilot.ResonanceLevel,
+		CharacterAttributes: l.Pilot.Attributes,
+		CurrentO2:           100,
+		Metadata:            map[string]interface{}{},
+	}
+	research := make([]interface{}, 0, len(l.Pilot.Research))
+	for _, node := range l.Pilot.Research {
+		research = append(research, node)
+	}
+	pilot.Metadata["unlocked_research"] = research
+	if pilot.SyncLevel < 1 {
+		pilot.SyncLevel = 1
+	}
+
+	if l.Vehicle == nil {
+		return service.MapVehicleToUnitStats(nil, nil, pilot)
+	}
+
+	v := &vehicle.Vehicle{
+		ID:    uuid.New(),
+		Name:  l.Name,
+		Class: vehicle.VehicleClass(l.Vehicle.Class),
+		Stats: vehicle.VehicleStats{HP: l.Vehicle.HP, Attack: l.Vehicle.Attack, Defense: l.Vehicle.Defense, Speed: l.Vehicle.Speed},
+	}
+
+	items := make([]vehicle.Item, 0, len(l.Items))
+	for i, li := range l.Items {
+		item := vehicle.Item{
+			// Stable IDs so repeated runs line up in the event log
+			ID:         uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s/%d", l.Name, i))),
+			Name:       li.Name,
+			ItemType:   vehicle.ItemTypePart,
+			IsEquipped: true,
+			Condition:  vehicle.ConditionPristine,
+			Stats: vehicle.ItemStats{
+				Attack:           li.Attack,
+				EnergyConsume:    li.EnergyConsume,
+				BonusHP:          li.BonusHP,
+				BonusAttack:      li.BonusAttack,
+				BonusDefense:     li.BonusDefense,
+				ShieldGeneration: li.ShieldGeneration,
+			},
+		}
+		if li.DamageType != "" {
+			dmgType := li.DamageType
+			item.DamageType = &dmgType
+		}
+		if li.Cooldown != nil {
+			item.Metadata = map[string]interface{}{"cooldown": float64(*li.Cooldown)}
+		}
+		items = append(items, item)
+	}
+
+	return service.MapVehicleToUnitStats(v, items, pilot)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ryudokung/Project-0/backend/internal/combat"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// balance-sim runs seeded Monte Carlo battles between player loadouts and enemy blueprints
+// for one or more balance configs and reports the results as CSV or JSON.
+//
+//	go run ./cmd/balance-sim -config configs/game_balance.yaml,configs/candidate.yaml -battles 2000 -format csv -out sim.csv
+func main() {
+	configs := flag.String("config", "configs/game_balance.yaml", "Comma-separated balance config files to compare")
+	enemiesPath := flag.String("enemies", "blueprints/enemies.yaml", "Enemy blueprints")
+	loadoutsPath := flag.String("loadouts", "configs/balance_loadouts.yaml", "Player loadouts")
+	enemyFilter := flag.String("enemy", "", "Comma-separated enemy names to include (default: all)")
+	battles := flag.Int("battles", 1000, "Battles per loadout/enemy matchup")
+	seed := flag.Int64("seed", 1, "Seed of the first battle; battle i uses seed+i")
+	maxTurns := flag.Int("max-turns", combat.DefaultMaxTurns, "Turn limit before a battle counts as a timeout")
+	enemyCount := flag.Int("enemy-count", 1, "Enemies per battle")
+	sensitivity := flag.Bool("sensitivity", false, "Also measure how each BalanceConfig field moves the results")
+	sensBattles := flag.Int("sens-battles", 200, "Battles per matchup for each sensitivity run")
+	delta := flag.Float64("delta", 0.1, "Relative change applied to each field in the sensitivity runs")
+	format := flag.String("format", "csv", "Output format: csv or json")
+	outPath := flag.String("out", "", "Output file (default: stdout)")
+	flag.Parse()
+
+	if *format != "csv" && *format != "json" {
+		fail(fmt.Errorf("unknown format %q", *format))
+	}
+
+	registry := game.NewBlueprintRegistry()
+	if err := registry.LoadEnemies(*enemiesPath); err != nil {
+		fail(err)
+	}
+	enemies := selectEnemies(registry, *enemyFilter)
+	if len(enemies) == 0 {
+		fail(fmt.Errorf("no enemies selected"))
+	}
+	loadouts, err := loadLoadouts(*loadoutsPath)
+	if err != nil {
+		fail(err)
+	}
+
+	opts := SimOptions{Battles: *battles, Seed: *seed, MaxTurns: *maxTurns, EnemyCount: *enemyCount}
+	report := Report{Battles: *battles, Seed: *seed}
+
+	for _, path := range strings.Split(*configs, ",") {
+		path = strings.TrimSpace(path)
+		cfg, err := loadConfig(path)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", path, err))
+		}
+		name := filepath.Base(path)
+		report.Configs = append(report.Configs, name)
+
+		fmt.Fprintf(os.Stderr, "[%s] %d loadouts x %d enemies x %d battles\n", name, len(loadouts), len(enemies), *battles)
+		report.Matchups = append(report.Matchups, runMatchups(name, cfg, loadouts, enemies, opts)...)
+
+		if *sensitivity {
+			sensOpts := opts
+			sensOpts.Battles = *sensBattles
+			report.Sensitivity = append(report.Sensitivity, runSensitivity(name, cfg, loadouts, enemies, sensOpts, *delta)...)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "json" {
+		err = writeJSON(out, report)
+	} else {
+		err = writeCSV(out, report)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// loadConfig reads and validates one balance file
+func loadConfig(path string) (combat.BalanceConfig, error) {
+	snapshot, err := combat.ReadBalanceConfig(path)
+	if err != nil {
+		return combat.BalanceConfig{}, err
+	}
+	return snapshot.Config, nil
+}
+
+func runMatchups(name string, cfg combat.BalanceConfig, loadouts []Loadout, enemies []game.EnemyBlueprint, opts SimOptions) []MatchupResult {
+	// Perturbed sensitivity configs may leave the validated ranges on purpose, so publish without validating
+	combat.SetBalance(&combat.BalanceSnapshot{Config: cfg, Version: name})
+
+	var results []MatchupResult
+	for _, loadout := range loadouts {
+		for _, enemy := range enemies {
+			results = append(results, simulate(name, loadout, enemy, opts))
+		}
+	}
+	return results
+}
+
+func runSensitivity(name string, cfg combat.BalanceConfig, loadouts []Loadout, enemies []game.EnemyBlueprint, opts SimOptions, delta float64) []SensitivityResult {
+	baseWinRate, baseTurns := aggregate(runMatchups(name, cfg, loadouts, enemies, opts))
+
+	var results []SensitivityResult
+	knobs := balanceKnobs(cfg)
+	for i, k := range knobs {
+		base := k.get(&cfg)
+		if base == 0 {
+			continue // Nothing to scale
+		}
+
+		perturbed := copyConfig(cfg)
+		k.set(&perturbed, base*(1+delta))
+		if k.get(&perturbed) == base {
+			// Small integers round back to themselves: move them by one step instead
+			k.set(&perturbed, base+math.Copysign(1, base))
+		}
+		actual := (k.get(&perturbed) - base) / base
+
+		fmt.Fprintf(os.Stderr, "[%s] sensitivity %d/%d %s\n", name, i+1, len(knobs), k.Path)
+		winRate, turns := aggregate(runMatchups(name, perturbed, loadouts, enemies, opts))
+
+		result := SensitivityResult{
+			Config:           name,
+			Field:            k.Path,
+			Base:             base,
+			Delta:            actual,
+			WinRate:          winRate,
+			WinRateDelta:     winRate - baseWinRate,
+			TurnsToKill:      turns,
+			TurnsToKillDelta: turns - baseTurns,
+		}
+		if baseWinRate > 0 {
+			result.Elasticity = (result.WinRateDelta / baseWinRate) / actual
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func selectEnemies(registry *game.BlueprintRegistry, filter string) []game.EnemyBlueprint {
+	wanted := map[string]bool{}
+	for _, name := range strings.Split(filter, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+
+	var enemies []game.EnemyBlueprint
+	for _, e := range registry.Enemies {
+		if len(wanted) == 0 || wanted[e.Name] {
+			enemies = append(enemies, e)
+		}
+	}
+	sort.Slice(enemies, func(i, j int) bool { return enemies[i].Name < enemies[j].Name })
+	return enemies
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "balance-sim: %v\n", err)
+	os.Exit(1)
+}
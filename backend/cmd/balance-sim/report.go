@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/ryudokung/Project-0/backend/internal/combat"
+)
+
+// Report is everything one run of the simulator produced
+type Report struct {
+	Configs     []string            `json:"configs"`
+	Battles     int                 `json:"battles_per_matchup"`
+	Seed        int64               `json:"seed"`
+	Matchups    []MatchupResult     `json:"matchups"`
+	Sensitivity []SensitivityResult `json:"sensitivity,omitempty"`
+}
+
+func writeJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// writeCSV writes the report in long format (config, loadout, enemy, metric, value) so designers
+// can pivot two configs side by side in a spreadsheet
+func writeCSV(w io.Writer, report Report) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"config", "loadout", "enemy", "metric", "value"}); err != nil {
+		return err
+	}
+
+	row := func(config, loadout, enemy, metric string, value float64) error {
+		return out.Write([]string{config, loadout, enemy, metric, strconv.FormatFloat(value, 'f', 4, 64)})
+	}
+
+	for _, m := range report.Matchups {
+		metrics := []struct {
+			name  string
+			value float64
+		}{
+			{"battles", float64(m.Battles)},
+			{"wins", float64(m.Wins)},
+			{"losses", float64(m.Losses)},
+			{"timeouts", float64(m.Timeouts)},
+			{"win_rate", m.WinRate},
+			{"mean_turns", m.MeanTurns},
+			{"mean_turns_to_kill", m.MeanTurnsToKill},
+		}
+		for _, metric := range metrics {
+			if err := row(m.Config, m.Loadout, m.Enemy, metric.name, metric.value); err != nil {
+				return err
+			}
+		}
+
+		for _, dmgType := range sortedDamageTypes(m.Damage) {
+			d := m.Damage[dmgType]
+			prefix := fmt.Sprintf("damage.%s.", dmgType)
+			for _, metric := range []struct {
+				name  string
+				value float64
+			}{
+				{"attacks", float64(d.Attacks)},
+				{"misses", float64(d.Misses)},
+				{"crits", float64(d.Crits)},
+				{"mean", d.Mean},
+				{"p10", d.P10},
+				{"p50", d.P50},
+				{"p90", d.P90},
+				{"max", d.Max},
+			} {
+				if err := row(m.Config, m.Loadout, m.Enemy, prefix+metric.name, metric.value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, s := range report.Sensitivity {
+		prefix := "sensitivity." + s.Field + "."
+		for _, metric := range []struct {
+			name  string
+			value float64
+		}{
+			{"base", s.Base},
+			{"delta", s.Delta},
+			{"win_rate", s.WinRate},
+			{"win_rate_delta", s.WinRateDelta},
+			{"turns_to_kill", s.TurnsToKill},
+			{"turns_to_kill_delta", s.TurnsToKillDelta},
+			{"elasticity", s.Elasticity},
+		} {
+			if err := row(s.Config, "*", "*", prefix+metric.name, metric.value); err != nil {
+				return err
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func sortedDamageTypes(damage map[combat.DamageType]*DamageStats) []combat.DamageType {
+	types := make([]combat.DamageType, 0, len(damage))
+	for t := range damage {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
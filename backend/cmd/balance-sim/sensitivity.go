@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ryudokung/Project-0/backend/internal/combat"
+)
+
+// knob is one numeric BalanceConfig field, addressed by its YAML path
+type knob struct {
+	Path string
+	get  func(cfg *combat.BalanceConfig) float64
+	set  func(cfg *combat.BalanceConfig, v float64)
+}
+
+// SensitivityResult is how much the aggregate results move when one field is scaled by (1 + Delta)
+type SensitivityResult struct {
+	Config           string  `json:"config"`
+	Field            string  `json:"field"`
+	Base             float64 `json:"base"`
+	Delta            float64 `json:"delta"`
+	WinRate          float64 `json:"win_rate"`
+	WinRateDelta     float64 `json:"win_rate_delta"`
+	TurnsToKill      float64 `json:"turns_to_kill"`
+	TurnsToKillDelta float64 `json:"turns_to_kill_delta"`
+	Elasticity       float64 `json:"elasticity"` // % change in win rate per % change in the field
+}
+
+// balanceKnobs lists every int/float field of BalanceConfig, including map entries, sorted by path
+func balanceKnobs(cfg combat.BalanceConfig) []knob {
+	var knobs []knob
+	collectKnobs(reflect.TypeOf(cfg), nil, "", &knobs, cfg)
+	sort.Slice(knobs, func(i, j int) bool { return knobs[i].Path < knobs[j].Path })
+	return knobs
+}
+
+// collectKnobs walks the struct type. Each step of steps resolves one level (field or map key) on a config value.
+func collectKnobs(t reflect.Type, steps []step, path string, knobs *[]knob, sample combat.BalanceConfig) {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			collectKnobs(f.Type, append(append([]step{}, steps...), step{field: i}), joinPath(path, name), knobs, sample)
+		}
+	case reflect.Map:
+		// Map entries present in the loaded config become knobs of their own
+		m := resolve(reflect.ValueOf(&sample).Elem(), steps)
+		keys := m.MapKeys()
+		for _, k := range keys {
+			collectKnobs(t.Elem(), append(append([]step{}, steps...), step{key: k, isKey: true}), joinPath(path, fmt.Sprint(k.Interface())), knobs, sample)
+		}
+	case reflect.Int, reflect.Float64:
+		s := append([]step{}, steps...)
+		*knobs = append(*knobs, knob{
+			Path: path,
+			get: func(cfg *combat.BalanceConfig) float64 {
+				v := resolve(reflect.ValueOf(cfg).Elem(), s)
+				if v.Kind() == reflect.Int {
+					return float64(v.Int())
+				}
+				return v.Float()
+			},
+			set: func(cfg *combat.BalanceConfig, v float64) {
+				assign(reflect.ValueOf(cfg).Elem(), s, v)
+			},
+		})
+	}
+}
+
+type step struct {
+	field int
+	key   reflect.Value
+	isKey bool
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func resolve(v reflect.Value, steps []step) reflect.Value {
+	for _, s := range steps {
+		if s.isKey {
+			v = v.MapIndex(s.key)
+		} else {
+			v = v.Field(s.field)
+		}
+	}
+	return v
+}
+
+// assign sets the numeric value at steps. Map values are not addressable, so entries are copied,
+// updated and written back.
+func assign(v reflect.Value, steps []step, value float64) {
+	if len(steps) == 0 {
+		if v.Kind() == reflect.Int {
+			v.SetInt(int64(math.Round(value)))
+		} else {
+			v.SetFloat(value)
+		}
+		return
+	}
+
+	s := steps[0]
+	if !s.isKey {
+		assign(v.Field(s.field), steps[1:], value)
+		return
+	}
+	entry := reflect.New(v.Type().Elem()).Elem()
+	entry.Set(v.MapIndex(s.key))
+	assign(entry, steps[1:], value)
+	v.SetMapIndex(s.key, entry)
+}
+
+// copyConfig deep-copies the maps so a perturbed config never leaks into the baseline
+func copyConfig(cfg combat.BalanceConfig) combat.BalanceConfig {
+	out := cfg
+	out.StatusEffects.Effects = make(map[combat.StatusEffectType]combat.StatusEffectConfig, len(cfg.StatusEffects.Effects))
+	for k, v := range cfg.StatusEffects.Effects {
+		out.StatusEffects.Effects[k] = v
+	}
+	out.Weapons.Cooldowns = make(map[combat.DamageType]int, len(cfg.Weapons.Cooldowns))
+	for k, v := range cfg.Weapons.Cooldowns {
+		out.Weapons.Cooldowns[k] = v
+	}
+	out.DamageTypes.Matrix = make(map[combat.DamageType]map[combat.DamageType]float64, len(cfg.DamageTypes.Matrix))
+	for attack, row := range cfg.DamageTypes.Matrix {
+		out.DamageTypes.Matrix[attack] = make(map[combat.DamageType]float64, len(row))
+		for armor, m := range row {
+			out.DamageTypes.Matrix[attack][armor] = m
+		}
+	}
+	out.HitModel.Attributes = make(map[string]combat.HitBonus, len(cfg.HitModel.Attributes))
+	for k, v := range cfg.HitModel.Attributes {
+		out.HitModel.Attributes[k] = v
+	}
+	out.HitModel.Research = make(map[string]combat.HitBonus, len(cfg.HitModel.Research))
+	for k, v := range cfg.HitModel.Research {
+		out.HitModel.Research[k] = v
+	}
+	out.DamageTypes.DefensePierce = make(map[combat.DamageType]float64, len(cfg.DamageTypes.DefensePierce))
+	for k, v := range cfg.DamageTypes.DefensePierce {
+		out.DamageTypes.DefensePierce[k] = v
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ryudokung/Project-0/backend/internal/combat"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// SimOptions controls one batch of battles per matchup
+type SimOptions struct {
+	Battles    int
+	Seed       int64
+	MaxTurns   int
+	EnemyCount int
+}
+
+// MatchupResult aggregates every battle between one loadout and one enemy blueprint under one config
+type MatchupResult struct {
+	Config          string                             `json:"config"`
+	Loadout         string                             `json:"loadout"`
+	Enemy           string                             `json:"enemy"`
+	Battles         int                                `json:"battles"`
+	Wins            int                                `json:"wins"`
+	Losses          int                                `json:"losses"`
+	Timeouts        int                                `json:"timeouts"`
+	WinRate         float64                            `json:"win_rate"`
+	MeanTurns       float64                            `json:"mean_turns"`
+	MeanTurnsToKill float64                            `json:"mean_turns_to_kill"` // Victories only
+	Damage          map[combat.DamageType]*DamageStats `json:"damage"`             // Player attacks by damage type
+}
+
+// DamageStats is the distribution of the player's hits of one damage type
+type DamageStats struct {
+	Attacks int     `json:"attacks"`
+	Misses  int     `json:"misses"`
+	Crits   int     `json:"crits"`
+	Mean    float64 `json:"mean"`
+	P10     float64 `json:"p10"`
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+	Max     float64 `json:"max"`
+
+	samples []float64
+}
+
+// simulate runs opts.Battles seeded battles for one matchup with the currently active balance config.
+// Battle i always uses seed opts.Seed+i, so two configs are compared on the same dice.
+func simulate(configName string, loadout Loadout, enemy game.EnemyBlueprint, opts SimOptions) MatchupResult {
+	service := combat.NewService(combat.NewEngine())
+	player := loadout.Stats(service)
+	foe := service.MapEnemyBlueprintToUnitStats(enemy)
+
+	result := MatchupResult{
+		Config:  configName,
+		Loadout: loadout.Name,
+		Enemy:   enemy.Name,
+		Battles: opts.Battles,
+		Damage:  map[combat.DamageType]*DamageStats{},
+	}
+
+	totalTurns, killTurns := 0, 0
+	for i := 0; i < opts.Battles; i++ {
+		session := service.NewSession(player, foe, opts.Seed+int64(i))
+		for n := 1; n < opts.EnemyCount; n++ {
+			service.AddUnit(session, combat.SideEnemy, foe)
+		}
+		summary := service.RunCommands(session, service.AutoCommand, opts.MaxTurns)
+
+		totalTurns += summary.Turns
+		switch summary.Outcome {
+		case combat.OutcomeVictory:
+			result.Wins++
+			killTurns += summary.Turns
+		case combat.OutcomeDefeat:
+			result.Losses++
+		default:
+			result.Timeouts++
+		}
+
+		for _, ev := range summary.Events {
+			if ev.Actor != combat.SidePlayer || ev.Skipped {
+				continue
+			}
+			stats := result.Damage[ev.DamageType]
+			if stats == nil {
+				stats = &DamageStats{}
+				result.Damage[ev.DamageType] = stats
+			}
+			stats.Attacks++
+			switch {
+			case ev.Result.IsMiss:
+				stats.Misses++
+			default:
+				if ev.Result.IsCritical {
+					stats.Crits++
+				}
+				stats.samples = append(stats.samples, float64(ev.Result.FinalDamage))
+			}
+		}
+	}
+
+	if opts.Battles > 0 {
+		result.WinRate = float64(result.Wins) / float64(opts.Battles)
+		result.MeanTurns = float64(totalTurns) / float64(opts.Battles)
+	}
+	if result.Wins > 0 {
+		result.MeanTurnsToKill = float64(killTurns) / float64(result.Wins)
+	}
+	for _, stats := range result.Damage {
+		stats.summarize()
+	}
+	return result
+}
+
+func (d *DamageStats) summarize() {
+	if len(d.samples) == 0 {
+		return
+	}
+	sort.Float64s(d.samples)
+	sum := 0.0
+	for _, v := range d.samples {
+		sum += v
+	}
+	d.Mean = sum / float64(len(d.samples))
+	d.P10 = percentile(d.samples, 0.10)
+	d.P50 = percentile(d.samples, 0.50)
+	d.P90 = percentile(d.samples, 0.90)
+	d.Max = d.samples[len(d.samples)-1]
+	d.samples = nil
+}
+
+// percentile uses nearest-rank on sorted samples
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// aggregate averages win rate and turns-to-kill over a set of matchups
+func aggregate(results []MatchupResult) (winRate, turnsToKill float64) {
+	wins, battles, killTurns := 0, 0, 0.0
+	for _, r := range results {
+		wins += r.Wins
+		battles += r.Battles
+		killTurns += r.MeanTurnsToKill * float64(r.Wins)
+	}
+	if battles > 0 {
+		winRate = float64(wins) / float64(battles)
+	}
+	if wins > 0 {
+		turnsToKill = killTurns / float64(wins)
+	}
+	return winRate, turnsToKill
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
@@ -98,7 +99,10 @@ func main() {
 	fmt.Printf("Attacker: %s (HP: %d, ATK: %d)\n", aVehicle.Class, aStats.HP, aStats.BaseAttack)
 	fmt.Printf("Defender: %s (HP: %d, DEF: %d)\n", dVehicle.Class, dStats.HP, dStats.TargetDefense)
 
-	for aStats.HP > 0 && dStats.HP > 0 {
+	session := combatService.NewSession(aStats, dStats, time.Now().UnixNano())
+	fmt.Printf("Battle Seed: %d\n", session.Seed)
+
+	for session.Outcome == combat.OutcomeOngoing {
 		fmt.Println("\nChoose Damage Type: [K]inetic, [E]nergy, [X]plosive")
 		fmt.Print("> ")
 		choice, _ := reader.ReadString('\n')
@@ -116,65 +120,52 @@ func main() {
 			dmgType = combat.Kinetic
 		}
 
-		// Attacker hits Defender
-		session := &combat.CombatSession{
-			PlayerStats: aStats,
-			EnemyStats:  dStats,
+		logStart := len(session.Log)
+		// Armed vehicles fire their best ready weapon of the chosen type
+		events, err := combatService.PlayCommand(session, combatService.CommandFor(session, dmgType))
+		if err != nil {
+			fmt.Printf("Cannot attack: %v\n", err)
+			continue
 		}
-		res := combatService.ExecuteAttack(session, dmgType)
-		aStats = session.PlayerStats
-		dStats = session.EnemyStats
-		
-		fmt.Printf("\n>> ATTACK! Type: %s\n", dmgType)
-		if res.IsMiss {
-			fmt.Println("MISS!")
-		} else {
+
+		fmt.Printf("\n-- TURN %d --\n", session.TurnCount)
+		for _, ev := range events {
+			name := "Attacker"
+			targetName := "Defender"
+			if ev.Actor == combat.SideEnemy {
+				name, targetName = "Defender", "Attacker"
+			}
+
+			if ev.Skipped {
+				fmt.Printf("\n>> %s is stalled and loses the turn!\n", name)
+				continue
+			}
+			fmt.Printf("\n>> %s attacks! Type: %s\n", name, ev.DamageType)
+			if ev.Result.IsMiss {
+				fmt.Println("MISS!")
+				continue
+			}
 			critStr := ""
-			if res.IsCritical {
+			if ev.Result.IsCritical {
 				critStr = " CRITICAL!"
 			}
-			fmt.Printf("DAMAGE: %d%s\n", res.FinalDamage, critStr)
-			if res.AppliedEffect != nil {
-				fmt.Printf("EFFECT APPLIED: %s (%d turns)\n", res.AppliedEffect.Type, res.AppliedEffect.Duration)
+			fmt.Printf("DAMAGE: %d%s\n", ev.Result.FinalDamage, critStr)
+			if ev.Result.AppliedEffect != nil {
+				fmt.Printf("EFFECT APPLIED: %s (%d turns)\n", ev.Result.AppliedEffect.Type, ev.Result.AppliedEffect.Duration)
 			}
+			fmt.Printf("%s HP remaining: %d\n", targetName, ev.TargetHP)
 		}
 
-		for _, l := range session.Log {
+		for _, l := range session.Log[logStart:] {
 			fmt.Printf("[EVENT] %s\n", l)
 		}
+	}
 
-		if dStats.HP <= 0 {
-			fmt.Println("\n*** DEFENDER DESTROYED! ***")
-			break
-		}
-
-		fmt.Printf("Defender HP remaining: %d\n", dStats.HP)
-
-		// Simple Counter Attack (Defender hits Attacker)
-		fmt.Println("\n-- Defender Counters! --")
-		counterSession := &combat.CombatSession{
-			PlayerStats: dStats,
-			EnemyStats:  aStats,
-		}
-		resCounter := combatService.ExecuteAttack(counterSession, combat.Kinetic)
-		dStats = counterSession.PlayerStats
-		aStats = counterSession.EnemyStats
-
-		if resCounter.IsMiss {
-			fmt.Println("Counter MISS!")
-		} else {
-			fmt.Printf("Counter DAMAGE: %d\n", resCounter.FinalDamage)
-		}
-
-		for _, l := range counterSession.Log {
-			fmt.Printf("[EVENT] %s\n", l)
-		}
-
-		if aStats.HP <= 0 {
-			fmt.Println("\n*** ATTACKER DESTROYED! ***")
-			break
-		}
-		fmt.Printf("Attacker HP remaining: %d\n", aStats.HP)
+	switch session.Outcome {
+	case combat.OutcomeVictory:
+		fmt.Println("\n*** DEFENDER DESTROYED! ***")
+	case combat.OutcomeDefeat:
+		fmt.Println("\n*** ATTACKER DESTROYED! ***")
 	}
 
 	fmt.Println("\n========================================")
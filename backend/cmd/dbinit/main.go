@@ -21,7 +21,10 @@ func main() {
 	fmt.Println("Dropping existing tables and types...")
 	dropQuery := `
 		DROP TABLE IF EXISTS exploration_nodes CASCADE;
+		DROP TABLE IF EXISTS combat_sessions CASCADE;
 		DROP TABLE IF EXISTS exploration_sessions CASCADE;
+		DROP TABLE IF EXISTS enemy_instances CASCADE;
+		DROP TABLE IF EXISTS encounters CASCADE;
 		DROP TABLE IF EXISTS expeditions CASCADE;
 		DROP TABLE IF EXISTS nodes CASCADE;
 		DROP TABLE IF EXISTS sub_sectors CASCADE;
@@ -54,6 +54,8 @@ func initialModel(db *sql.DB) model {
 	_ = blueprints.LoadNodes("blueprints/nodes.yaml")
 	_ = blueprints.LoadEnemies("blueprints/enemies.yaml")
 	_ = blueprints.LoadExpeditions("blueprints/expeditions.yaml")
+	_ = blueprints.LoadLoot("blueprints/loot.yaml")
+	_ = blueprints.LoadHazards("blueprints/hazards.yaml")
 
 	service := exploration.NewService(repo, vehicleUseCase, gameRepo, blueprints)
 
@@ -87,7 +89,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "n":
 			// String a new encounter
 			ctx := context.Background()
-			encounter, err := m.service.GenerateNewEncounter(ctx, m.expedition.ID, m.vehicleID)
+			encounter, err := m.service.GenerateNewEncounter(ctx, m.expedition.ID, m.vehicleID, "")
 			if err != nil {
 				m.err = err
 				return m, nil
@@ -29,7 +29,7 @@ func main() {
 		MaxHP:             1000,
 		BaseAttack:        100,  // High attack to kill boss mech
 		TargetDefense:     10,
-		DefenseEfficiency: combat.GlobalBalance.BaseStats.DefaultDefenseEfficiency,
+		DefenseEfficiency: combat.Balance().BaseStats.DefaultDefenseEfficiency,
 		Accuracy:          100,
 		Evasion:           20,
 		IsVehicle:         true,
@@ -63,79 +63,49 @@ func main() {
 		},
 	}
 
-	session := &combat.CombatSession{
-		PlayerStats:  playerStats,
-		EnemyStats:   bossStats,
-		IsScripted:   true,
-		ScriptEvents: scriptEvents,
-	}
+	session := service.NewSession(playerStats, bossStats, 42)
+	session.IsScripted = true
+	session.ScriptEvents = scriptEvents
 
 	fmt.Println("\n--- PHASE 1: MECH VS MECH ---")
 	
-	turn := 1
-	for {
-		fmt.Printf("\n[TURN %d]\n", turn)
+	for session.Outcome == combat.OutcomeOngoing && session.TurnCount < 50 { // Safety break
+		fmt.Printf("\n[TURN %d]\n", session.TurnCount+1)
 		fmt.Printf("Player HP: %d (%s) | Boss HP: %d (%s)\n", 
 			session.PlayerStats.HP, 
 			func() string { if session.PlayerStats.IsVehicle { return "MECH" }; return "HUMAN" }(),
 			session.EnemyStats.HP, 
 			func() string { if session.EnemyStats.IsVehicle { return "MECH" }; return "HUMAN" }())
 
-		// Player Attacks
-		res := service.ExecuteAttack(session, combat.Kinetic)
-		fmt.Printf(">> Player attacks: %d damage (Gauge: %.1f%%)\n", res.FinalDamage, session.PlayerStats.ResonanceGauge)
-		
 		// Check for Resonance Activation
-		if !session.PlayerStats.IsResonanceActive && session.PlayerStats.ResonanceGauge >= 100 {
-			service.ActivateResonance(session)
+		if service.ActivateResonance(session) {
 			fmt.Println("!!! PLAYER ACTIVATED RESONANCE MODE !!!")
 		}
 
-		// Check for logs (Scripted Events)
-		for _, log := range session.Log {
-			fmt.Printf("!!! EVENT: %s\n", log)
-		}
-		session.Log = nil
-
-		if session.EnemyStats.HP <= 0 && !session.EnemyStats.IsVehicle {
-			fmt.Println("\n*** BOSS DEFEATED! ***")
-			break
-		}
-
-		// Boss Counters
-		// To counter-attack correctly, we swap roles but keep the session's Player/Enemy context
-		// We'll use a temporary session for the counter-attack calculation
-		counterSession := &combat.CombatSession{
-			PlayerStats:  session.EnemyStats,  // Boss is attacker
-			EnemyStats:   session.PlayerStats, // Player is defender
-			IsScripted:   true,
-			ScriptEvents: scriptEvents,
+		logStart := len(session.Log)
+		for _, ev := range service.PlayTurn(session, combat.Kinetic) {
+			if ev.Skipped {
+				fmt.Printf("-- %s stalled, turn lost\n", ev.Actor)
+				continue
+			}
+			if ev.Actor == combat.SidePlayer {
+				fmt.Printf(">> Player attacks: %d damage (Gauge: %.1f%%)\n", ev.Result.FinalDamage, session.PlayerStats.ResonanceGauge)
+				continue
+			}
+			fmt.Printf("<< Boss counters: %d damage\n", ev.Result.FinalDamage)
 		}
-		resC := service.ExecuteAttack(counterSession, combat.Kinetic)
-		
-		// Sync back the results
-		session.EnemyStats = counterSession.PlayerStats
-		session.PlayerStats = counterSession.EnemyStats
 
-		// Build gauge from taking damage too
-		session.PlayerStats.ResonanceGauge += float64(resC.FinalDamage) * combat.GlobalBalance.Resonance.GainRateTaken
-		if session.PlayerStats.ResonanceGauge > 100 {
-			session.PlayerStats.ResonanceGauge = 100
-		}
-
-		fmt.Printf("<< Boss counters: %d damage\n", resC.FinalDamage)
-		
-		for _, log := range counterSession.Log {
+		// Check for logs (Scripted Events)
+		for _, log := range session.Log[logStart:] {
 			fmt.Printf("!!! EVENT: %s\n", log)
 		}
+	}
 
-		if session.PlayerStats.HP <= 0 && !session.PlayerStats.IsVehicle {
-			fmt.Println("\n*** PLAYER DESTROYED! ***")
-			break
-		}
-
-		turn++
-		if turn > 50 { break } // Safety break
+	switch session.Outcome {
+	case combat.OutcomeVictory:
+		fmt.Println("\n*** BOSS DEFEATED! ***")
+	case combat.OutcomeDefeat:
+		fmt.Println("\n*** PLAYER DESTROYED! ***")
 	}
 
 	fmt.Println("\n--- TEST COMPLETE ---")
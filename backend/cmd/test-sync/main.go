@@ -48,7 +48,7 @@ func main() {
 		fmt.Printf("Base Defense: %d -> Effective Defense: %d\n", v.Stats.Defense, stats.TargetDefense)
 		
 		// Verify multiplier
-		expectedMultiplier := combat.GlobalBalance.Progression.BaseSyncRate + (float64(lv-1) * combat.GlobalBalance.Progression.SyncRatePerLevel)
+		expectedMultiplier := combat.Balance().Progression.BaseSyncRate + (float64(lv-1) * combat.Balance().Progression.SyncRatePerLevel)
 		fmt.Printf("Expected Multiplier: %.2f\n", expectedMultiplier)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
@@ -51,6 +52,7 @@ type model struct {
 	
 	attacker      *vehicle.Vehicle
 	defender      *vehicle.Vehicle
+	session       *combat.CombatSession
 	aStats        combat.UnitStats
 	dStats        combat.UnitStats
 	aPilot        *game.PilotStats
@@ -96,6 +98,7 @@ func initialModel(db *sql.DB) model {
 		vehicleRepo:   vehicleRepo,
 		gameRepo:      gameRepo,
 		combatService: combatService,
+		session:       combatService.NewSession(aStats, dStats, time.Now().UnixNano()),
 		attacker:      aVehicle,
 		defender:      dVehicle,
 		aStats:        aStats,
@@ -135,62 +138,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleAttack(dmgType combat.DamageType) (model, tea.Cmd) {
-	// Attacker hits Defender
-	session := &combat.CombatSession{
-		PlayerStats: m.aStats,
-		EnemyStats:  m.dStats,
+	logStart := len(m.session.Log)
+	// Armed vehicles fire their best ready weapon of the chosen type
+	events, err := m.combatService.PlayCommand(m.session, m.combatService.CommandFor(m.session, dmgType))
+	if err != nil {
+		m.logs = append(m.logs, "Cannot attack: "+err.Error())
+		return m, nil
 	}
-	res := m.combatService.ExecuteAttack(session, dmgType)
-	m.aStats = session.PlayerStats
-	m.dStats = session.EnemyStats
+	m.aStats = m.session.PlayerStats
+	m.dStats = m.session.EnemyStats
 
-	logMsg := fmt.Sprintf("Attacker used %s: ", dmgType)
-	if res.IsMiss {
-		logMsg += "MISS!"
-	} else {
-		logMsg += fmt.Sprintf("HIT for %d damage!", res.FinalDamage)
-		if res.IsCritical {
-			logMsg += " CRITICAL!"
+	for _, ev := range events {
+		logMsg := fmt.Sprintf("Attacker used %s: ", ev.DamageType)
+		if ev.Actor == combat.SideEnemy {
+			logMsg = "Defender Counters: "
 		}
-		if res.AppliedEffect != nil {
-			logMsg += fmt.Sprintf(" [%s Applied]", res.AppliedEffect.Type)
+		if ev.Skipped {
+			logMsg += "STALLED!"
+		} else if ev.Result.IsMiss {
+			logMsg += "MISS!"
+		} else {
+			logMsg += fmt.Sprintf("HIT for %d damage!", ev.Result.FinalDamage)
+			if ev.Result.IsCritical {
+				logMsg += " CRITICAL!"
+			}
+			if ev.Result.AppliedEffect != nil {
+				logMsg += fmt.Sprintf(" [%s Applied]", ev.Result.AppliedEffect.Type)
+			}
 		}
+		m.logs = append(m.logs, logMsg)
 	}
-	m.logs = append(m.logs, logMsg)
-	for _, l := range session.Log {
+	for _, l := range m.session.Log[logStart:] {
 		m.logs = append(m.logs, "[EVENT] "+l)
 	}
 
-	if m.dStats.HP <= 0 {
-		m.dStats.HP = 0
+	switch m.session.Outcome {
+	case combat.OutcomeVictory:
 		m.gameOver = true
 		m.winner = "ATTACKER"
 		m.logs = append(m.logs, ">>> DEFENDER DESTROYED! <<<")
-		return m, nil
-	}
-
-	// Counter Attack
-	counterSession := &combat.CombatSession{
-		PlayerStats: m.dStats,
-		EnemyStats:  m.aStats,
-	}
-	resC := m.combatService.ExecuteAttack(counterSession, combat.Kinetic)
-	m.dStats = counterSession.PlayerStats
-	m.aStats = counterSession.EnemyStats
-
-	logMsgC := "Defender Counters: "
-	if resC.IsMiss {
-		logMsgC += "MISS!"
-	} else {
-		logMsgC += fmt.Sprintf("HIT for %d damage!", resC.FinalDamage)
-	}
-	m.logs = append(m.logs, logMsgC)
-	for _, l := range counterSession.Log {
-		m.logs = append(m.logs, "[EVENT] "+l)
-	}
-
-	if m.aStats.HP <= 0 {
-		m.aStats.HP = 0
+	case combat.OutcomeDefeat:
 		m.gameOver = true
 		m.winner = "DEFENDER"
 		m.logs = append(m.logs, ">>> ATTACKER DESTROYED! <<<")
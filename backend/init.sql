@@ -172,7 +172,6 @@ CREATE TABLE IF NOT EXISTS stars (
 -- Nodes (Encounters on an Expedition)
 CREATE TYPE node_type AS ENUM ('STANDARD', 'RESOURCE', 'COMBAT', 'ANOMALY', 'OUTPOST', 'NARRATIVE', 'REST', 'BOSS');
 CREATE TYPE zone_type AS ENUM ('ORBITAL', 'SURFACE', 'EVA', 'CORRIDOR');
-CREATE TYPE hazard_type AS ENUM ('NONE', 'EMP_STORM', 'CORROSIVE_RAIN', 'SOLAR_FLARE', 'VOID_ECHO');
 
 -- Universe Map Structure
 CREATE TABLE IF NOT EXISTS sectors (
@@ -195,7 +194,7 @@ CREATE TABLE IF NOT EXISTS sub_sectors (
     description TEXT,
     rewards TEXT[],
     requirements TEXT[],
-    allowed_modes TEXT[], -- PILOT, VEHICLE, TANK, etc.
+    allowed_modes TEXT[], -- PILOT, EXOSUIT or vehicle types (MECH, TANK, ...); VEHICLE admits any vehicle type
     requires_atmosphere BOOLEAN DEFAULT FALSE,
     terrain terrain_type DEFAULT 'SPACE',
     detection_threshold INTEGER DEFAULT 1000,
@@ -234,7 +233,13 @@ CREATE TABLE IF NOT EXISTS expeditions (
     title VARCHAR(200),
     description TEXT,
     goal TEXT,
-    status VARCHAR(20) DEFAULT 'ACTIVE', -- ACTIVE, COMPLETED, FAILED
+    status VARCHAR(20) DEFAULT 'ACTIVE', -- ACTIVE, IN_COMBAT, EXTRACTING, COMPLETED, FAILED, ABANDONED
+    difficulty INTEGER DEFAULT 1,
+    seed BIGINT DEFAULT 0, -- Procedural timeline seed (0 = handcrafted)
+    radar_level INTEGER DEFAULT 1,
+    timeline_length INTEGER DEFAULT 0,
+    alarm_level INTEGER DEFAULT 0, -- 0-100; rises with detections and loud choices, decays with stealth
+    site_snapshot JSONB, -- Sector, sub-sector and planet location the timeline was generated from
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -246,19 +251,37 @@ CREATE TABLE IF NOT EXISTS encounters (
     description TEXT,
     visual_prompt TEXT,
     image_url TEXT,
-    enemy_id UUID, -- References vehicles(id) for NPC enemies
+    enemy_id UUID, -- References enemy_instances(id)
+    node_id UUID, -- Timeline node this encounter was generated from
     terrain terrain_type DEFAULT 'SPACE',
     detection_threshold INTEGER DEFAULT 1000,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Enemies spawned per encounter from an enemy blueprint; HP persists between battles
+CREATE TABLE IF NOT EXISTS enemy_instances (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    expedition_id UUID REFERENCES expeditions(id),
+    encounter_id UUID REFERENCES encounters(id),
+    blueprint_id VARCHAR(100) NOT NULL,
+    name VARCHAR(100),
+    scale DOUBLE PRECISION DEFAULT 1.0,
+    hp INTEGER NOT NULL,
+    max_hp INTEGER NOT NULL,
+    attack INTEGER NOT NULL,
+    defense INTEGER NOT NULL,
+    speed INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE IF NOT EXISTS nodes (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     expedition_id UUID REFERENCES expeditions(id),
     name VARCHAR(100) NOT NULL,
     type node_type NOT NULL,
     zone zone_type DEFAULT 'SURFACE',
-    hazard hazard_type DEFAULT 'NONE',
+    hazard VARCHAR(50) DEFAULT 'NONE', -- NONE or a hazard ID from blueprints/hazards.yaml
     environment_description TEXT, -- e.g., "Acid Rain Desert", "Neon Slums"
     difficulty_multiplier DECIMAL(3, 2) DEFAULT 1.0,
     position_index INTEGER NOT NULL, -- Order on the "Expedition"
@@ -272,6 +295,7 @@ CREATE TABLE IF NOT EXISTS nodes (
     is_end BOOLEAN DEFAULT FALSE,
     enemy_blueprint VARCHAR(100),
     enemy_count INTEGER DEFAULT 1,
+    blueprint_id VARCHAR(100) DEFAULT '', -- Node blueprint (procedural) or expedition node ID (handcrafted, matched by next_nodes)
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -314,6 +338,21 @@ CREATE TABLE IF NOT EXISTS combat_logs (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Combat Sessions (Persisted multi-turn battles, one per encounter)
+CREATE TABLE IF NOT EXISTS combat_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id),
+    encounter_id UUID UNIQUE REFERENCES encounters(id),
+    enemy_id UUID, -- Enemy blueprint/instance fought in this session
+    seed BIGINT NOT NULL,
+    state JSONB NOT NULL, -- Serialized CombatSession: stats, effects, log, RNG position
+    outcome VARCHAR(20) DEFAULT '', -- '' (ongoing), VICTORY, DEFEAT, TIMEOUT
+    rewards_granted BOOLEAN DEFAULT FALSE, -- End-of-battle record, DDS and payout fully applied
+    version INT NOT NULL DEFAULT 0, -- Optimistic lock: incremented on every update
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Gacha Pity System
 CREATE TABLE IF NOT EXISTS gacha_stats (
     user_id UUID PRIMARY KEY REFERENCES users(id),
@@ -357,6 +396,8 @@ CREATE INDEX IF NOT EXISTS idx_vehicles_owner ON vehicles(owner_id);
 CREATE INDEX IF NOT EXISTS idx_saga_user ON saga_transactions(user_id);
 CREATE INDEX IF NOT EXISTS idx_saga_idempotency ON saga_transactions(idempotency_key);
 CREATE INDEX IF NOT EXISTS idx_combat_user ON combat_logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_combat_sessions_user ON combat_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_enemy_instances_encounter ON enemy_instances(encounter_id);
 CREATE INDEX IF NOT EXISTS idx_combat_expires ON combat_logs(expires_at) WHERE is_permanent = FALSE;
 CREATE INDEX IF NOT EXISTS idx_items_owner ON items(owner_id);
 CREATE INDEX IF NOT EXISTS idx_items_character ON items(character_id);
@@ -376,6 +417,7 @@ $$ language 'plpgsql';
 CREATE TRIGGER update_items_updated_at BEFORE UPDATE ON items FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_characters_updated_at BEFORE UPDATE ON characters FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_exploration_sessions_updated_at BEFORE UPDATE ON exploration_sessions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_combat_sessions_updated_at BEFORE UPDATE ON combat_sessions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
 --------------------------------------------------------------------------------
 -- INITIAL SEED DATA (Single Source of Truth)
@@ -400,7 +442,7 @@ VALUES ('a0000000-0000-0000-0000-000000000001', 'SOL GATE', 'The industrial gate
 ON CONFLICT (id) DO NOTHING;
 
 INSERT INTO sub_sectors (id, sector_id, type, name, description, rewards, requirements, allowed_modes, requires_atmosphere, suitability_pilot, suitability_vehicle, coordinates_x, coordinates_y) 
-VALUES ('b0000000-0000-0000-0000-000000000001', 'a0000000-0000-0000-0000-000000000001', 'STATION', 'Outpost 01', 'A standard refueling station for independent scavengers.', '{"Scrap Metal", "Fuel Isotopes"}', '{}', '{"PILOT", "VEHICLE"}', FALSE, 100, 20, 40, 30)
+VALUES ('b0000000-0000-0000-0000-000000000001', 'a0000000-0000-0000-0000-000000000001', 'STATION', 'Outpost 01', 'A standard refueling station for independent scavengers.', '{"Scrap Metal", "Fuel Isotopes"}', '{}', '{"PILOT", "SPEEDER", "SHIP"}', FALSE, 100, 20, 40, 30)
 ON CONFLICT (id) DO NOTHING;
 
 -- 4. Sample Narrative Expedition
@@ -1,17 +1,34 @@
 package combat
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
 	"gopkg.in/yaml.v3"
 )
 
 type BalanceConfig struct {
+	Version string `yaml:"version"` // Human label; the snapshot version adds a content hash
+
 	Resonance struct {
-		GainRateDealt            float64 `yaml:"gain_rate_dealt"`
-		GainRateTaken            float64 `yaml:"gain_rate_taken"`
-		BonusAccuracyPerLevel    int     `yaml:"bonus_accuracy_per_level"`
-		BonusEvasionPerLevel     int     `yaml:"bonus_evasion_per_level"`
+		GainRateDealt             float64 `yaml:"gain_rate_dealt"`
+		GainRateTaken             float64 `yaml:"gain_rate_taken"`
+		BonusAccuracyPerLevel     int     `yaml:"bonus_accuracy_per_level"`
+		BonusEvasionPerLevel      int     `yaml:"bonus_evasion_per_level"`
 		ResonanceDamageMultiplier float64 `yaml:"resonance_damage_multiplier"`
+		Duration                  int     `yaml:"duration"`        // Turns Resonance Mode lasts
+		Cooldown                  int     `yaml:"cooldown"`        // Turns before it can be activated again
+		StressPerTurn             int     `yaml:"stress_per_turn"` // Pilot Stress gained per active turn
 	} `yaml:"resonance"`
 
 	Progression struct {
@@ -20,28 +37,347 @@ type BalanceConfig struct {
 	} `yaml:"progression"`
 
 	ScaleSuppression struct {
-		HumanVsMechDamageReduction        float64 `yaml:"human_vs_mech_damage_reduction"`
-		MechVsHumanDamageMultiplier       float64 `yaml:"mech_vs_human_damage_multiplier"`
+		HumanVsMechDamageReduction          float64 `yaml:"human_vs_mech_damage_reduction"`
+		MechVsHumanDamageMultiplier         float64 `yaml:"mech_vs_human_damage_multiplier"`
 		ResonantHumanVsMechDamageMultiplier float64 `yaml:"resonant_human_vs_mech_damage_multiplier"`
-		ResonantHumanDeflectionRate       float64 `yaml:"resonant_human_deflection_rate"`
+		ResonantHumanDeflectionRate         float64 `yaml:"resonant_human_deflection_rate"`
 	} `yaml:"scale_suppression"`
 
 	BaseStats struct {
-		DefaultAccuracy         int     `yaml:"default_accuracy"`
+		DefaultAccuracy          int     `yaml:"default_accuracy"`
 		DefaultDefenseEfficiency float64 `yaml:"default_defense_efficiency"`
-		ForcedSurvivalHP        int     `yaml:"forced_survival_hp"`
-		BossPhase2HP            int     `yaml:"boss_phase_2_hp"`
-		BossPhase2Attack        int     `yaml:"boss_phase_2_attack"`
+		ForcedSurvivalHP         int     `yaml:"forced_survival_hp"`
+		BossPhase2HP             int     `yaml:"boss_phase_2_hp"`
+		BossPhase2Attack         int     `yaml:"boss_phase_2_attack"`
 		BossPhase2ResonanceLevel int     `yaml:"boss_phase_2_resonance_level"`
 	} `yaml:"base_stats"`
+
+	Shields struct {
+		CapacityPerGeneration int     `yaml:"capacity_per_generation"` // Max shield points per point of ShieldGeneration
+		EnergyStripMultiplier float64 `yaml:"energy_strip_multiplier"` // Shield points removed per point of Energy damage
+		VoidBypassRatio       float64 `yaml:"void_bypass_ratio"`       // Share of Void damage that ignores shields
+	} `yaml:"shields"`
+
+	StatusEffects struct {
+		ApplyChance int                                     `yaml:"apply_chance"` // % chance on a non-critical hit
+		Effects     map[StatusEffectType]StatusEffectConfig `yaml:"effects"`
+	} `yaml:"status_effects"`
+
+	Weapons struct {
+		BaseEnergy  int                `yaml:"base_energy"`  // Energy pool of an armed vehicle
+		EnergyRegen int                `yaml:"energy_regen"` // Restored at the start of each of the unit's turns
+		Cooldowns   map[DamageType]int `yaml:"cooldowns"`    // Turns a weapon sits out after firing, by damage type
+	} `yaml:"weapons"`
+
+	Durability struct {
+		PerHPLost float64 `yaml:"per_hp_lost"` // Item durability lost per point of vehicle HP lost in battle
+	} `yaml:"durability"`
+
+	HitModel struct {
+		BaseCritChance     int                 `yaml:"base_crit_chance"`     // %
+		AccuracyPerCrit    int                 `yaml:"accuracy_per_crit"`    // Attacker accuracy points per +1% crit
+		CritMultiplier     float64             `yaml:"crit_multiplier"`      // Damage multiplier of a critical hit
+		AccuracyPerEvasion int                 `yaml:"accuracy_per_evasion"` // Attacker accuracy points that cancel 1% of defender evasion
+		MaxMissChance      int                 `yaml:"max_miss_chance"`      // %
+		Attributes         map[string]HitBonus `yaml:"attributes"`           // Per point of a pilot character attribute
+		Research           map[string]HitBonus `yaml:"research"`             // Per unlocked Engineering Matrix node
+	} `yaml:"hit_model"`
+
+	DamageTypes struct {
+		Matrix        map[DamageType]map[DamageType]float64 `yaml:"matrix"`         // Attack type -> defender armor type -> multiplier (missing = 1.0)
+		DefensePierce map[DamageType]float64                `yaml:"defense_pierce"` // Share of defense ignored, by attack type
+		ClassArmor    map[string]DamageType                 `yaml:"class_armor"`    // Vehicle/enemy class -> armor type
+	} `yaml:"damage_types"`
+
+	EVA struct {
+		BaseHP            int     `yaml:"base_hp"` // Bare pilot stats, before the exosuit
+		BaseAttack        int     `yaml:"base_attack"`
+		BaseDefense       int     `yaml:"base_defense"`
+		BaseSpeed         int     `yaml:"base_speed"`
+		O2PerTurn         float64 `yaml:"o2_per_turn"`        // O2 drained each turn on foot
+		SuffocationDamage int     `yaml:"suffocation_damage"` // HP lost per turn once O2 runs out
+	} `yaml:"eva"`
+
+	Squads struct {
+		MaxSize     int     `yaml:"max_size"`     // Units per side, lead included
+		SplashRatio float64 `yaml:"splash_ratio"` // Share of an Explosive hit dealt to the target's squadmates
+	} `yaml:"squads"`
 }
 
-var GlobalBalance BalanceConfig
+// StatusEffectConfig describes how a status effect stacks and what it does per stack
+type StatusEffectConfig struct {
+	Duration        int          `yaml:"duration"`   // Turns
+	MaxStacks       int          `yaml:"max_stacks"` // 0 or 1 = no stacking
+	Stacking        StackingRule `yaml:"stacking"`
+	AttackModifier  float64      `yaml:"attack_modifier"`  // Multiplier delta per stack, e.g. -0.2
+	DefenseModifier float64      `yaml:"defense_modifier"` // Multiplier delta per stack
+	SpeedModifier   float64      `yaml:"speed_modifier"`   // Multiplier delta per stack
+	EvasionModifier float64      `yaml:"evasion_modifier"` // Multiplier delta per stack
+	DamagePerTurn   int          `yaml:"damage_per_turn"`  // Per stack, applied when the unit's turn starts
+	SkipTurn        bool         `yaml:"skip_turn"`
+}
 
-func LoadBalanceConfig(path string) error {
+// BalanceSnapshot is one loaded, validated balance config. Snapshots are never modified after
+// they are published, so readers can hold on to one for as long as they need.
+type BalanceSnapshot struct {
+	Config   BalanceConfig `json:"config"`
+	Version  string        `json:"version"`
+	Source   string        `json:"source"`
+	LoadedAt time.Time     `json:"loaded_at"`
+}
+
+var (
+	activeBalance atomic.Pointer[BalanceSnapshot]
+	zeroBalance   = &BalanceSnapshot{}
+)
+
+// CurrentBalance returns the snapshot the combat code is running on (an empty one until a config is loaded)
+func CurrentBalance() *BalanceSnapshot {
+	if snapshot := activeBalance.Load(); snapshot != nil {
+		return snapshot
+	}
+	return zeroBalance
+}
+
+// Balance returns the active config. Treat it as read-only.
+func Balance() *BalanceConfig {
+	return &CurrentBalance().Config
+}
+
+// SetBalance publishes a snapshot without validating it and returns the previous one.
+// Tools and tests use it; the server goes through BalanceProvider.
+func SetBalance(snapshot *BalanceSnapshot) *BalanceSnapshot {
+	return activeBalance.Swap(snapshot)
+}
+
+// ParseBalanceConfig decodes a balance file, rejecting unknown keys and out-of-range values
+func ParseBalanceConfig(data []byte) (BalanceConfig, error) {
+	var cfg BalanceConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return BalanceConfig{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return BalanceConfig{}, err
+	}
+	return cfg, nil
+}
+
+// ReadBalanceConfig loads and validates a balance file into a snapshot without publishing it
+func ReadBalanceConfig(path string) (*BalanceSnapshot, error) {
 	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseBalanceConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:])[:12]
+	if cfg.Version != "" {
+		version = cfg.Version + "@" + version[:8]
+	}
+	return &BalanceSnapshot{Config: cfg, Version: version, Source: path, LoadedAt: time.Now()}, nil
+}
+
+// LoadBalanceConfig reads, validates and publishes a balance file (for one-shot tools)
+func LoadBalanceConfig(path string) error {
+	snapshot, err := ReadBalanceConfig(path)
 	if err != nil {
 		return err
 	}
-	return yaml.Unmarshal(data, &GlobalBalance)
+	SetBalance(snapshot)
+	return nil
+}
+
+// Validate checks required fields and value ranges. Optional sections may stay zero and fall back
+// to their defaults.
+func (c BalanceConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	ratio := func(name string, v float64) {
+		check(v >= 0 && v <= 1, "%s must be between 0 and 1, got %v", name, v)
+	}
+	nonNegative := func(name string, v float64) {
+		check(v >= 0, "%s must not be negative, got %v", name, v)
+	}
+
+	check(c.Progression.BaseSyncRate > 0, "progression.base_sync_rate is required")
+	nonNegative("progression.sync_rate_per_level", c.Progression.SyncRatePerLevel)
+
+	nonNegative("resonance.gain_rate_dealt", c.Resonance.GainRateDealt)
+	nonNegative("resonance.gain_rate_taken", c.Resonance.GainRateTaken)
+	nonNegative("resonance.bonus_accuracy_per_level", float64(c.Resonance.BonusAccuracyPerLevel))
+	nonNegative("resonance.bonus_evasion_per_level", float64(c.Resonance.BonusEvasionPerLevel))
+	nonNegative("resonance.resonance_damage_multiplier", c.Resonance.ResonanceDamageMultiplier)
+	nonNegative("resonance.duration", float64(c.Resonance.Duration))
+	nonNegative("resonance.cooldown", float64(c.Resonance.Cooldown))
+	nonNegative("resonance.stress_per_turn", float64(c.Resonance.StressPerTurn))
+
+	// Zero here would silently zero out cross-scale damage
+	check(c.ScaleSuppression.HumanVsMechDamageReduction > 0, "scale_suppression.human_vs_mech_damage_reduction is required")
+	ratio("scale_suppression.human_vs_mech_damage_reduction", c.ScaleSuppression.HumanVsMechDamageReduction)
+	check(c.ScaleSuppression.MechVsHumanDamageMultiplier > 0, "scale_suppression.mech_vs_human_damage_multiplier is required")
+	check(c.ScaleSuppression.ResonantHumanVsMechDamageMultiplier > 0, "scale_suppression.resonant_human_vs_mech_damage_multiplier is required")
+	check(c.ScaleSuppression.ResonantHumanDeflectionRate > 0, "scale_suppression.resonant_human_deflection_rate is required")
+	ratio("scale_suppression.resonant_human_deflection_rate", c.ScaleSuppression.ResonantHumanDeflectionRate)
+
+	check(c.BaseStats.DefaultAccuracy > 0, "base_stats.default_accuracy is required")
+	check(c.BaseStats.DefaultDefenseEfficiency > 0, "base_stats.default_defense_efficiency is required")
+	ratio("base_stats.default_defense_efficiency", c.BaseStats.DefaultDefenseEfficiency)
+	check(c.BaseStats.ForcedSurvivalHP > 0, "base_stats.forced_survival_hp is required")
+	nonNegative("base_stats.boss_phase_2_hp", float64(c.BaseStats.BossPhase2HP))
+	nonNegative("base_stats.boss_phase_2_attack", float64(c.BaseStats.BossPhase2Attack))
+	nonNegative("base_stats.boss_phase_2_resonance_level", float64(c.BaseStats.BossPhase2ResonanceLevel))
+
+	nonNegative("shields.capacity_per_generation", float64(c.Shields.CapacityPerGeneration))
+	nonNegative("shields.energy_strip_multiplier", c.Shields.EnergyStripMultiplier)
+	ratio("shields.void_bypass_ratio", c.Shields.VoidBypassRatio)
+
+	check(c.StatusEffects.ApplyChance >= 0 && c.StatusEffects.ApplyChance <= 100, "status_effects.apply_chance must be between 0 and 100, got %d", c.StatusEffects.ApplyChance)
+	for t, e := range c.StatusEffects.Effects {
+		switch t {
+		case Overheat, ArmorBreach, EngineStall:
+		default:
+			errs = append(errs, fmt.Errorf("status_effects.effects: unknown effect %q", t))
+		}
+		check(e.Duration > 0, "status_effects.effects.%s.duration must be positive", t)
+		nonNegative(fmt.Sprintf("status_effects.effects.%s.max_stacks", t), float64(e.MaxStacks))
+		switch e.Stacking {
+		case "", StackRefresh, StackAdd, StackExtend:
+		default:
+			errs = append(errs, fmt.Errorf("status_effects.effects.%s.stacking: unknown rule %q", t, e.Stacking))
+		}
+		nonNegative(fmt.Sprintf("status_effects.effects.%s.damage_per_turn", t), float64(e.DamagePerTurn))
+	}
+
+	nonNegative("weapons.base_energy", float64(c.Weapons.BaseEnergy))
+	nonNegative("weapons.energy_regen", float64(c.Weapons.EnergyRegen))
+	for t, cd := range c.Weapons.Cooldowns {
+		check(isDamageType(t), "weapons.cooldowns: unknown damage type %q", t)
+		nonNegative(fmt.Sprintf("weapons.cooldowns.%s", t), float64(cd))
+	}
+
+	nonNegative("hit_model.base_crit_chance", float64(c.HitModel.BaseCritChance))
+	nonNegative("hit_model.accuracy_per_crit", float64(c.HitModel.AccuracyPerCrit))
+	nonNegative("hit_model.accuracy_per_evasion", float64(c.HitModel.AccuracyPerEvasion))
+	check(c.HitModel.CritMultiplier == 0 || c.HitModel.CritMultiplier >= 1, "hit_model.crit_multiplier must be at least 1, got %v", c.HitModel.CritMultiplier)
+	check(c.HitModel.MaxMissChance >= 0 && c.HitModel.MaxMissChance <= 100, "hit_model.max_miss_chance must be between 0 and 100, got %d", c.HitModel.MaxMissChance)
+	for node := range c.HitModel.Research {
+		check(game.MatrixNodeByID(node) != nil, "hit_model.research: unknown matrix node %q", node)
+	}
+
+	for attack, row := range c.DamageTypes.Matrix {
+		check(isDamageType(attack), "damage_types.matrix: unknown damage type %q", attack)
+		for armor, m := range row {
+			check(isDamageType(armor), "damage_types.matrix.%s: unknown armor type %q", attack, armor)
+			check(m > 0, "damage_types.matrix.%s.%s must be positive, got %v", attack, armor, m)
+		}
+	}
+	for t, p := range c.DamageTypes.DefensePierce {
+		check(isDamageType(t), "damage_types.defense_pierce: unknown damage type %q", t)
+		ratio(fmt.Sprintf("damage_types.defense_pierce.%s", t), p)
+	}
+	for class, armor := range c.DamageTypes.ClassArmor {
+		check(isDamageType(armor), "damage_types.class_armor.%s: unknown armor type %q", class, armor)
+	}
+
+	nonNegative("durability.per_hp_lost", c.Durability.PerHPLost)
+
+	nonNegative("eva.base_hp", float64(c.EVA.BaseHP))
+	nonNegative("eva.base_attack", float64(c.EVA.BaseAttack))
+	nonNegative("eva.base_defense", float64(c.EVA.BaseDefense))
+	nonNegative("eva.base_speed", float64(c.EVA.BaseSpeed))
+	nonNegative("eva.o2_per_turn", c.EVA.O2PerTurn)
+	nonNegative("eva.suffocation_damage", float64(c.EVA.SuffocationDamage))
+
+	nonNegative("squads.max_size", float64(c.Squads.MaxSize))
+	ratio("squads.splash_ratio", c.Squads.SplashRatio)
+
+	return errors.Join(errs...)
+}
+
+func isDamageType(t DamageType) bool {
+	switch t {
+	case Kinetic, Energy, Explosive, Void:
+		return true
+	}
+	return false
+}
+
+// BalanceProvider owns the balance file of a running server: it validates every load and only
+// publishes configs that pass, so a bad edit keeps the previous version live.
+type BalanceProvider struct {
+	path    string
+	mu      sync.Mutex // Serialises reloads
+	modTime time.Time
+}
+
+// NewBalanceProvider loads the file once and fails if it is missing or invalid
+func NewBalanceProvider(path string) (*BalanceProvider, error) {
+	p := &BalanceProvider{path: path}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Current returns the published snapshot
+func (p *BalanceProvider) Current() *BalanceSnapshot {
+	return CurrentBalance()
+}
+
+// Reload re-reads the file and publishes it if valid
+func (p *BalanceProvider) Reload() (*BalanceSnapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, err
+	}
+	p.modTime = info.ModTime() // Remember failed edits too, so Watch does not retry them every tick
+
+	snapshot, err := ReadBalanceConfig(p.path)
+	if err != nil {
+		return nil, err
+	}
+	SetBalance(snapshot)
+	log.Printf("Balance: loaded %s (version %s)", p.path, snapshot.Version)
+	return snapshot, nil
+}
+
+// Watch polls the file and reloads it when it changes, until ctx is cancelled
+func (p *BalanceProvider) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(p.path)
+			if err != nil {
+				log.Printf("Balance: failed to stat %s: %v", p.path, err)
+				continue
+			}
+			p.mu.Lock()
+			changed := !info.ModTime().Equal(p.modTime)
+			p.mu.Unlock()
+			if !changed {
+				continue
+			}
+			if _, err := p.Reload(); err != nil {
+				log.Printf("Balance: keeping version %s, reload failed: %v", CurrentBalance().Version, err)
+			}
+		}
+	}
 }
@@ -0,0 +1,59 @@
+package combat
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// BalanceHandler exposes the live balance version and an admin-only reload
+type BalanceHandler struct {
+	provider   *BalanceProvider
+	adminToken string
+}
+
+func NewBalanceHandler(provider *BalanceProvider, adminToken string) *BalanceHandler {
+	return &BalanceHandler{provider: provider, adminToken: adminToken}
+}
+
+// GetBalance reports which balance config the server is running
+func (h *BalanceHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshot := h.provider.Current()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version":   snapshot.Version,
+		"source":    snapshot.Source,
+		"loaded_at": snapshot.LoadedAt,
+	})
+}
+
+// Reload re-reads the balance file. Requires the X-Admin-Token header; disabled when no token is configured.
+func (h *BalanceHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := r.Header.Get("X-Admin-Token")
+	if h.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	snapshot, err := h.provider.Reload()
+	if err != nil {
+		// The previous config stays live
+		http.Error(w, "Reload failed: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version":   snapshot.Version,
+		"loaded_at": snapshot.LoadedAt,
+	})
+}
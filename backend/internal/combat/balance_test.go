@@ -0,0 +1,101 @@
+package combat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withBalance publishes a modified copy of the active config for the duration of the test
+func withBalance(t *testing.T, modify func(cfg *BalanceConfig)) {
+	t.Helper()
+	prev := CurrentBalance()
+	cfg := prev.Config
+	modify(&cfg)
+	SetBalance(&BalanceSnapshot{Config: cfg, Version: "test"})
+	t.Cleanup(func() { SetBalance(prev) })
+}
+
+func TestShippedBalanceConfigIsValid(t *testing.T) {
+	snapshot, err := ReadBalanceConfig("../../configs/game_balance.yaml")
+	if err != nil {
+		t.Fatalf("game_balance.yaml: %v", err)
+	}
+	if snapshot.Version == "" {
+		t.Error("expected a version")
+	}
+}
+
+func TestParseBalanceConfigRejectsBadValues(t *testing.T) {
+	data, err := os.ReadFile("../../configs/game_balance.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := string(data)
+
+	cases := map[string]string{
+		"out of range":  strings.Replace(valid, "void_bypass_ratio: 1.0", "void_bypass_ratio: 1.5", 1),
+		"missing field": strings.Replace(valid, "base_sync_rate: 0.5", "base_sync_rate: 0", 1),
+		"unknown key":   strings.Replace(valid, "shields:", "shields:\n  capacity_per_generaton: 5", 1),
+		"unknown type":  strings.Replace(valid, "VOID: 3", "PLASMA: 3", 1),
+	}
+	for name, doc := range cases {
+		if doc == valid {
+			t.Fatalf("%s: replacement did not apply", name)
+		}
+		if _, err := ParseBalanceConfig([]byte(doc)); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestBalanceProviderReloadKeepsLastGoodConfig(t *testing.T) {
+	prev := CurrentBalance()
+	t.Cleanup(func() { SetBalance(prev) })
+
+	data, err := os.ReadFile("../../configs/game_balance.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "balance.yaml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewBalanceProvider(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := provider.Current()
+
+	// An invalid edit is rejected and the previous version stays live
+	if err := os.WriteFile(path, []byte("squads:\n  splash_ratio: 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Reload(); err == nil {
+		t.Fatal("expected invalid config to be rejected")
+	}
+	if provider.Current() != first {
+		t.Fatal("invalid config replaced the live one")
+	}
+
+	// A valid edit is published under a new version
+	edited := strings.Replace(string(data), "splash_ratio: 0.5", "splash_ratio: 0.25", 1)
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := provider.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Version == first.Version || Balance().Squads.SplashRatio != 0.25 {
+		t.Errorf("expected new version with splash 0.25, got %s / %v", second.Version, Balance().Squads.SplashRatio)
+	}
+
+	// Results are stamped with the version that produced them
+	res := NewEngineWithSeed(1).CalculateDamage(UnitStats{BaseAttack: 10, IsVehicle: true}, UnitStats{IsVehicle: true}, Kinetic)
+	if res.BalanceVersion != second.Version {
+		t.Errorf("result stamped %q, want %q", res.BalanceVersion, second.Version)
+	}
+}
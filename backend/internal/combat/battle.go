@@ -0,0 +1,220 @@
+package combat
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Side identifies which half of a CombatSession is acting
+type Side string
+
+const (
+	SidePlayer Side = "PLAYER"
+	SideEnemy  Side = "ENEMY"
+)
+
+type BattleOutcome string
+
+const (
+	OutcomeOngoing BattleOutcome = ""
+	OutcomeVictory BattleOutcome = "VICTORY"
+	OutcomeDefeat  BattleOutcome = "DEFEAT"
+	OutcomeTimeout BattleOutcome = "TIMEOUT"
+)
+
+// DefaultMaxTurns caps RunBattle when the caller does not provide a limit
+const DefaultMaxTurns = 50
+
+// TurnEvent records a single action taken during a battle
+type TurnEvent struct {
+	Turn       int          `json:"turn"`
+	Actor      Side         `json:"actor"`
+	DamageType DamageType   `json:"damage_type"`
+	WeaponID   *uuid.UUID   `json:"weapon_id,omitempty"`
+	Result     CombatResult `json:"result"`
+	ActorIndex  int          `json:"actor_index"`
+	TargetIndex int          `json:"target_index"`
+	TargetHP   int          `json:"target_hp"`
+	Skipped    bool         `json:"skipped,omitempty"` // Actor lost the turn (e.g. ENGINE_STALL)
+}
+
+// BattleSummary is the end-of-battle report returned to drivers and clients
+type BattleSummary struct {
+	Outcome  BattleOutcome `json:"outcome"`
+	Turns    int           `json:"turns"`
+	Seed     int64         `json:"seed"`
+	PlayerHP int           `json:"player_hp"`
+	EnemyHP  int           `json:"enemy_hp"`
+	Events   []TurnEvent   `json:"events"`
+	Log      []string      `json:"log"`
+}
+
+// PlayerAction picks the player's damage type for the next turn (used by RunBattle)
+type PlayerAction func(session *CombatSession) DamageType
+
+// PlayTurn runs one full turn with the player lead attacking the first enemy standing with dmgType (see CommandFor)
+func (s *Service) PlayTurn(session *CombatSession, dmgType DamageType) []TurnEvent {
+	events, _ := s.PlayCommand(session, s.CommandFor(session, dmgType))
+	return events
+}
+
+// PlayCommand runs one full turn: every standing unit acts in initiative order until one side falls.
+// The player lead follows cmd; wingmen and enemies choose their own target, weapon and damage type.
+// An unusable weapon in cmd, or no weapon while the lead has one ready, is rejected before anything happens.
+func (s *Service) PlayCommand(session *CombatSession, cmd PlayerCommand) ([]TurnEvent, error) {
+	if session.Outcome != OutcomeOngoing {
+		return nil, nil
+	}
+	// Energy regenerates before the lead acts, so check against next turn's pool
+	next := session.PlayerStats
+	RegenerateEnergy(&next)
+	if cmd.WeaponID != nil {
+		weapon := findWeapon(&next, *cmd.WeaponID)
+		if weapon == nil {
+			return nil, fmt.Errorf("weapon is not equipped on the attacking vehicle")
+		}
+		if err := checkWeapon(&next, weapon, session.TurnCount+1); err != nil {
+			return nil, err
+		}
+	} else if pickWeapon(&next, "", session.TurnCount+1) != nil {
+		return nil, fmt.Errorf("armed units must attack with an equipped weapon")
+	} else if session.DamageTypeDisabled(cmd.DamageType) {
+		return nil, fmt.Errorf("%s attacks are disabled by the hazard", cmd.DamageType)
+	}
+
+	session.TurnCount++
+	s.handleScriptedEvents(session)
+	var events []TurnEvent
+
+	for _, actor := range Initiative(session) {
+		unit := session.Unit(actor)
+		if unit.HP <= 0 {
+			continue // Fell earlier this turn
+		}
+
+		// Status effects tick at the start of the actor's turn
+		if actor == playerLead {
+			s.drainO2(session)
+		}
+		RegenerateShields(unit)
+		RegenerateEnergy(unit)
+		skipped := TickEffects(unit, &session.Log, unitName(actor))
+		s.handleScriptedEvents(session)
+		if skipped {
+			events = append(events, TurnEvent{Turn: session.TurnCount, Actor: actor.Side, ActorIndex: actor.Index, Skipped: true})
+			if s.checkOutcome(session) != OutcomeOngoing {
+				break
+			}
+			continue
+		}
+		if s.checkOutcome(session) != OutcomeOngoing {
+			break
+		}
+		if unit.HP <= 0 {
+			continue
+		}
+
+		target, actionType, weapon, ok := s.chooseAction(session, actor, cmd)
+		if !ok {
+			continue
+		}
+
+		var weaponID *uuid.UUID
+		if weapon != nil {
+			fire(unit, weapon, session.TurnCount)
+			weaponID = &weapon.ItemID
+		}
+
+		result := s.resolveAttack(session, actor, target, actionType, weapon)
+
+		events = append(events, TurnEvent{
+			Turn:        session.TurnCount,
+			Actor:       actor.Side,
+			ActorIndex:  actor.Index,
+			TargetIndex: target.Index,
+			DamageType:  actionType,
+			WeaponID:    weaponID,
+			Result:      result,
+			TargetHP:    session.Unit(target).HP,
+		})
+
+		if s.checkOutcome(session) != OutcomeOngoing {
+			break
+		}
+	}
+
+	s.tickResonance(session)
+	session.Events = append(session.Events, events...)
+	return events, nil
+}
+
+// ChooseEnemyAction is the enemy AI against the player lead: it answers raised shields with Energy
+// and otherwise fires Kinetic, unless a script forced a damage type
+func (s *Service) ChooseEnemyAction(session *CombatSession) DamageType {
+	return s.enemyActionAgainst(session, &session.PlayerStats)
+}
+
+func (s *Service) enemyActionAgainst(session *CombatSession, target *UnitStats) DamageType {
+	if session.EnemyDamageType != "" {
+		return session.usableType(session.EnemyDamageType)
+	}
+	return session.usableType(counterType(target))
+}
+
+// RunBattle plays turns until the battle ends or maxTurns is reached, then returns the summary.
+// Resonance is activated automatically as soon as the player's gauge is full.
+func (s *Service) RunBattle(session *CombatSession, action PlayerAction, maxTurns int) BattleSummary {
+	return s.RunCommands(session, func(session *CombatSession) PlayerCommand {
+		return s.CommandFor(session, action(session))
+	}, maxTurns)
+}
+
+// RunCommands is RunBattle for full player commands (weapons, targets); see AutoCommand for an auto-pilot
+func (s *Service) RunCommands(session *CombatSession, next func(session *CombatSession) PlayerCommand, maxTurns int) BattleSummary {
+	if maxTurns <= 0 {
+		maxTurns = DefaultMaxTurns
+	}
+
+	for session.Outcome == OutcomeOngoing {
+		if session.TurnCount >= maxTurns {
+			session.Outcome = OutcomeTimeout
+			break
+		}
+		if !session.PlayerStats.IsResonanceActive {
+			s.ActivateResonance(session)
+		}
+		if _, err := s.PlayCommand(session, next(session)); err != nil {
+			// Unusable command: fall back to the auto-pilot rather than stalling the battle
+			if _, err := s.PlayCommand(session, s.AutoCommand(session)); err != nil {
+				session.TurnCount++ // The lead can do nothing at all: lose the turn so maxTurns still ends the fight
+			}
+		}
+	}
+
+	return s.Summary(session)
+}
+
+// Summary reports the current state of the battle
+func (s *Service) Summary(session *CombatSession) BattleSummary {
+	return BattleSummary{
+		Outcome:  session.Outcome,
+		Turns:    session.TurnCount,
+		Seed:     session.Seed,
+		PlayerHP: session.PlayerStats.HP,
+		EnemyHP:  session.EnemyStats.HP,
+		Events:   session.Events,
+		Log:      session.Log,
+	}
+}
+
+// checkOutcome sets the session outcome: victory once every enemy is down, defeat once the player lead falls
+func (s *Service) checkOutcome(session *CombatSession) BattleOutcome {
+	switch {
+	case len(session.Standing(SideEnemy)) == 0:
+		session.Outcome = OutcomeVictory
+	case session.PlayerStats.HP <= 0:
+		session.Outcome = OutcomeDefeat
+	}
+	return session.Outcome
+}
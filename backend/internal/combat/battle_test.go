@@ -0,0 +1,96 @@
+package combat
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInitiativeUsesSpeed(t *testing.T) {
+	session := &CombatSession{
+		PlayerStats: UnitStats{Speed: 30},
+		EnemyStats:  UnitStats{Speed: 60},
+	}
+	order := Initiative(session)
+	if order[0].Side != SideEnemy {
+		t.Errorf("Faster enemy should act first, got %v", order)
+	}
+
+	session.EnemyStats.Speed = 30
+	order = Initiative(session)
+	if order[0].Side != SidePlayer {
+		t.Errorf("Ties should go to the player, got %v", order)
+	}
+}
+
+func TestPlayTurnEnemyCountersAndTurnCount(t *testing.T) {
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 500, MaxHP: 500, BaseAttack: 20, Accuracy: 100, Speed: 50, IsPlayer: true}
+	enemy := UnitStats{HP: 500, MaxHP: 500, BaseAttack: 20, Accuracy: 100, Speed: 10}
+	session := service.NewSession(player, enemy, 99)
+
+	events := service.PlayTurn(session, Kinetic)
+
+	if session.TurnCount != 1 {
+		t.Errorf("Expected TurnCount 1, got %d", session.TurnCount)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected both sides to act, got %d events", len(events))
+	}
+	if events[0].Actor != SidePlayer || events[1].Actor != SideEnemy {
+		t.Errorf("Expected player then enemy, got %s then %s", events[0].Actor, events[1].Actor)
+	}
+	if session.PlayerStats.HP != 500-events[1].Result.FinalDamage {
+		t.Errorf("Enemy hit was not applied to the player")
+	}
+}
+
+func TestRunBattleIsReproducible(t *testing.T) {
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 300, MaxHP: 300, BaseAttack: 40, TargetDefense: 10, DefenseEfficiency: 0.5, Accuracy: 80, Evasion: 15, Speed: 50, IsPlayer: true}
+	enemy := UnitStats{HP: 250, MaxHP: 250, BaseAttack: 35, TargetDefense: 10, DefenseEfficiency: 0.5, Accuracy: 80, Evasion: 15, Speed: 45}
+	kinetic := func(*CombatSession) DamageType { return Kinetic }
+
+	a := service.RunBattle(service.NewSession(player, enemy, 2024), kinetic, 0)
+	b := service.RunBattle(service.NewSession(player, enemy, 2024), kinetic, 0)
+
+	if a.Outcome == OutcomeOngoing {
+		t.Fatalf("Battle should have ended")
+	}
+	if a.Outcome != b.Outcome || a.Turns != b.Turns || a.PlayerHP != b.PlayerHP || a.EnemyHP != b.EnemyHP {
+		t.Errorf("Same seed produced different battles: %+v vs %+v", a, b)
+	}
+}
+
+func TestResumedSessionContinuesSameFight(t *testing.T) {
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 400, MaxHP: 400, BaseAttack: 30, DefenseEfficiency: 0.5, Accuracy: 80, Evasion: 20, Speed: 50, IsPlayer: true}
+	enemy := UnitStats{HP: 400, MaxHP: 400, BaseAttack: 30, DefenseEfficiency: 0.5, Accuracy: 80, Evasion: 20, Speed: 40}
+
+	straight := service.NewSession(player, enemy, 77)
+	for i := 0; i < 4; i++ {
+		service.PlayTurn(straight, Energy)
+	}
+
+	// Persist after two turns, then reload and continue
+	resumed := service.NewSession(player, enemy, 77)
+	service.PlayTurn(resumed, Energy)
+	service.PlayTurn(resumed, Energy)
+	service.Snapshot(resumed)
+
+	data, err := json.Marshal(resumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded CombatSession
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	service.Resume(&loaded)
+	service.PlayTurn(&loaded, Energy)
+	service.PlayTurn(&loaded, Energy)
+
+	if loaded.PlayerStats.HP != straight.PlayerStats.HP || loaded.EnemyStats.HP != straight.EnemyStats.HP {
+		t.Errorf("Resumed fight diverged: player %d vs %d, enemy %d vs %d",
+			loaded.PlayerStats.HP, straight.PlayerStats.HP, loaded.EnemyStats.HP, straight.EnemyStats.HP)
+	}
+}
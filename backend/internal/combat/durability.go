@@ -0,0 +1,50 @@
+package combat
+
+import "github.com/google/uuid"
+
+const defaultDurabilityPerHP = 1.0
+
+func durabilityPerHP() float64 {
+	if perHP := Balance().Durability.PerHPLost; perHP > 0 {
+		return perHP
+	}
+	return defaultDurabilityPerHP
+}
+
+// DurabilityDamage converts the HP each player vehicle lost in the battle into durability damage,
+// keyed by vehicle ID. A vehicle the pilot was ejected from counts as fully destroyed.
+func (s *Service) DurabilityDamage(session *CombatSession) map[uuid.UUID]int {
+	damage := map[uuid.UUID]int{}
+
+	add := func(vehicleID *uuid.UUID, start, end UnitStats) {
+		if vehicleID == nil || !start.IsVehicle {
+			return
+		}
+		lost := start.HP - end.HP
+		if !end.IsVehicle {
+			lost = start.HP
+		}
+		if lost <= 0 {
+			return
+		}
+		damage[*vehicleID] += int(float64(lost) * durabilityPerHP())
+	}
+
+	add(session.VehicleID, session.StartPlayer, session.PlayerStats)
+	for i, end := range session.PlayerSquad {
+		if i < len(session.SquadVehicleIDs) && i < len(session.StartPlayerSquad) {
+			add(&session.SquadVehicleIDs[i], session.StartPlayerSquad[i], end)
+		}
+	}
+	return damage
+}
+
+// EnemyVehicleDurabilityDamage is DurabilityDamage for the lead enemy when it is another player's
+// vehicle (the standalone attack endpoint)
+func (s *Service) EnemyVehicleDurabilityDamage(session *CombatSession) int {
+	lost := session.StartEnemy.HP - session.EnemyStats.HP
+	if !session.StartEnemy.IsVehicle || lost <= 0 {
+		return 0
+	}
+	return int(float64(lost) * durabilityPerHP())
+}
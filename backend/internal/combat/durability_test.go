@@ -0,0 +1,69 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
+)
+
+func TestDurabilityDamageFromHPLost(t *testing.T) {
+	service := NewService(NewEngine())
+	lead, wingman := uuid.New(), uuid.New()
+
+	player := UnitStats{HP: 200, MaxHP: 200, IsVehicle: true, IsPlayer: true}
+	session := service.NewSession(player, UnitStats{HP: 100, MaxHP: 100}, 1)
+	session.VehicleID = &lead
+	service.AddUnit(session, SidePlayer, UnitStats{HP: 150, MaxHP: 150, IsVehicle: true})
+	session.SquadVehicleIDs = []uuid.UUID{wingman}
+
+	session.PlayerStats.HP = 120
+	session.PlayerSquad[0].HP = 150
+
+	damage := service.DurabilityDamage(session)
+	if damage[lead] != int(80*durabilityPerHP()) {
+		t.Errorf("Expected lead durability damage %d, got %d", int(80*durabilityPerHP()), damage[lead])
+	}
+	if _, ok := damage[wingman]; ok {
+		t.Error("Untouched wingman should take no durability damage")
+	}
+
+	// Ejected pilot: the vehicle is written off completely
+	session.PlayerStats = UnitStats{HP: 20, MaxHP: 20, IsVehicle: false}
+	if got := service.DurabilityDamage(session)[lead]; got != int(200*durabilityPerHP()) {
+		t.Errorf("Expected full durability damage after eject, got %d", got)
+	}
+}
+
+func TestEnemyVehicleDurabilityDamage(t *testing.T) {
+	service := NewService(NewEngine())
+	session := service.NewSession(UnitStats{HP: 100, MaxHP: 100}, UnitStats{HP: 300, MaxHP: 300, IsVehicle: true}, 1)
+	session.EnemyStats.HP = 250
+	if got := service.EnemyVehicleDurabilityDamage(session); got != int(50*durabilityPerHP()) {
+		t.Errorf("Expected %d durability damage, got %d", int(50*durabilityPerHP()), got)
+	}
+
+	session.StartEnemy.IsVehicle = false
+	if got := service.EnemyVehicleDurabilityDamage(session); got != 0 {
+		t.Errorf("Non-vehicle enemies take no durability damage, got %d", got)
+	}
+}
+
+func TestBrokenPartsGiveNoBonus(t *testing.T) {
+	service := NewService(NewEngine())
+	v := &vehicle.Vehicle{Stats: vehicle.VehicleStats{HP: 100, Attack: 10, Defense: 10, Speed: 20}}
+	kinetic := "KINETIC"
+	items := []vehicle.Item{
+		{IsEquipped: true, Condition: vehicle.ConditionWorn, Stats: vehicle.ItemStats{BonusAttack: 5}},
+		{IsEquipped: true, Condition: vehicle.ConditionBroken, Stats: vehicle.ItemStats{BonusAttack: 50, BonusHP: 100}},
+		{IsEquipped: true, Condition: vehicle.ConditionBroken, DamageType: &kinetic},
+	}
+
+	stats := service.MapVehicleToUnitStats(v, items, nil)
+	if stats.BaseAttack != 15 || stats.MaxHP != 100 {
+		t.Errorf("Broken part should be ignored, got ATK %d / HP %d", stats.BaseAttack, stats.MaxHP)
+	}
+	if len(stats.Weapons) != 0 {
+		t.Error("Broken weapons cannot fire")
+	}
+}
@@ -0,0 +1,133 @@
+package combat
+
+import "fmt"
+
+// StackingRule controls what happens when an effect is applied to a unit that already has it
+type StackingRule string
+
+const (
+	StackRefresh StackingRule = "refresh" // Keep one stack, reset duration
+	StackAdd     StackingRule = "stack"   // Add a stack up to MaxStacks, reset duration
+	StackExtend  StackingRule = "extend"  // Keep one stack, add the new duration on top
+)
+
+// defaultEffectDurations are used when the balance file does not configure an effect
+var defaultEffectDurations = map[StatusEffectType]int{
+	Overheat:    2,
+	ArmorBreach: 99, // Permanent
+	EngineStall: 1,
+}
+
+// effectConfig returns the balance entry for an effect, falling back to its default duration
+func effectConfig(balance *BalanceConfig, t StatusEffectType) StatusEffectConfig {
+	if cfg, ok := balance.StatusEffects.Effects[t]; ok {
+		return cfg
+	}
+	return StatusEffectConfig{Duration: defaultEffectDurations[t], Stacking: StackRefresh}
+}
+
+func newStatusEffect(balance *BalanceConfig, t StatusEffectType) *StatusEffect {
+	return &StatusEffect{Type: t, Duration: effectConfig(balance, t).Duration, Stacks: 1}
+}
+
+// ApplyStatusEffect adds an effect to a unit following the effect's stacking rule
+func ApplyStatusEffect(balance *BalanceConfig, unit *UnitStats, effect StatusEffect) {
+	cfg := effectConfig(balance, effect.Type)
+	if effect.Stacks < 1 {
+		effect.Stacks = 1
+	}
+
+	for i := range unit.Effects {
+		existing := &unit.Effects[i]
+		if existing.Type != effect.Type || existing.Stat != effect.Stat {
+			continue
+		}
+
+		switch cfg.Stacking {
+		case StackAdd:
+			existing.Stacks += effect.Stacks
+			if cfg.MaxStacks > 0 && existing.Stacks > cfg.MaxStacks {
+				existing.Stacks = cfg.MaxStacks
+			}
+			existing.Duration = effect.Duration
+		case StackExtend:
+			existing.Duration += effect.Duration
+		default:
+			if effect.Duration > existing.Duration {
+				existing.Duration = effect.Duration
+			}
+		}
+		return
+	}
+
+	unit.Effects = append(unit.Effects, effect)
+}
+
+// EffectiveStats returns a copy of the unit with all active status modifiers applied
+func EffectiveStats(unit UnitStats) UnitStats {
+	attackMod, defenseMod, speedMod, evasionMod := 1.0, 1.0, 1.0, 1.0
+	for _, effect := range unit.Effects {
+		cfg := effectConfig(Balance(), effect.Type)
+		stacks := float64(effect.Stacks)
+		attackMod += cfg.AttackModifier * stacks
+		defenseMod += cfg.DefenseModifier * stacks
+		speedMod += cfg.SpeedModifier * stacks
+		evasionMod += cfg.EvasionModifier * stacks
+
+		switch effect.Stat {
+		case "attack":
+			attackMod += effect.Modifier * stacks
+		case "defense":
+			defenseMod += effect.Modifier * stacks
+		case "speed":
+			speedMod += effect.Modifier * stacks
+		case "evasion":
+			evasionMod += effect.Modifier * stacks
+		}
+	}
+
+	unit.BaseAttack = scaleStat(unit.BaseAttack, attackMod)
+	unit.TargetDefense = scaleStat(unit.TargetDefense, defenseMod)
+	unit.Speed = scaleStat(unit.Speed, speedMod)
+	unit.Evasion = scaleStat(unit.Evasion, evasionMod)
+	return unit
+}
+
+func scaleStat(value int, mod float64) int {
+	if mod < 0 {
+		mod = 0
+	}
+	return int(float64(value) * mod)
+}
+
+// TickEffects runs the start-of-turn step for a unit: damage over time is applied, durations
+// count down and expired effects are removed. It reports whether the unit loses this turn.
+func TickEffects(unit *UnitStats, log *[]string, name string) bool {
+	skip := false
+	remaining := unit.Effects[:0]
+
+	for _, effect := range unit.Effects {
+		cfg := effectConfig(Balance(), effect.Type)
+
+		if cfg.DamagePerTurn > 0 {
+			dmg := cfg.DamagePerTurn * effect.Stacks
+			unit.HP -= dmg
+			if unit.HP < 0 {
+				unit.HP = 0
+			}
+			*log = append(*log, fmt.Sprintf("[STATUS] %s takes %d %s damage", name, dmg, effect.Type))
+		}
+		if cfg.SkipTurn {
+			skip = true
+			*log = append(*log, fmt.Sprintf("[STATUS] %s is disabled by %s", name, effect.Type))
+		}
+
+		effect.Duration--
+		if effect.Duration > 0 {
+			remaining = append(remaining, effect)
+		}
+	}
+
+	unit.Effects = remaining
+	return skip
+}
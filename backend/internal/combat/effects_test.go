@@ -0,0 +1,65 @@
+package combat
+
+import (
+	"testing"
+)
+
+func withStatusConfig(t *testing.T, effects map[StatusEffectType]StatusEffectConfig) {
+	t.Helper()
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.StatusEffects.Effects = effects
+	})
+}
+
+func TestApplyStatusEffectStacking(t *testing.T) {
+	withStatusConfig(t, map[StatusEffectType]StatusEffectConfig{
+		ArmorBreach: {Duration: 5, MaxStacks: 2, Stacking: StackAdd, DefenseModifier: -0.25},
+		Overheat:    {Duration: 2, Stacking: StackExtend},
+	})
+
+	unit := UnitStats{TargetDefense: 100}
+	for i := 0; i < 3; i++ {
+		ApplyStatusEffect(Balance(), &unit, *newStatusEffect(Balance(), ArmorBreach))
+	}
+	if len(unit.Effects) != 1 || unit.Effects[0].Stacks != 2 {
+		t.Fatalf("Expected ARMOR_BREACH capped at 2 stacks, got %+v", unit.Effects)
+	}
+	if def := EffectiveStats(unit).TargetDefense; def != 50 {
+		t.Errorf("Expected 2 stacks of -25%% DEF to leave 50, got %d", def)
+	}
+
+	ApplyStatusEffect(Balance(), &unit, *newStatusEffect(Balance(), Overheat))
+	ApplyStatusEffect(Balance(), &unit, *newStatusEffect(Balance(), Overheat))
+	if unit.Effects[1].Duration != 4 {
+		t.Errorf("Expected extended OVERHEAT duration 4, got %d", unit.Effects[1].Duration)
+	}
+}
+
+func TestTickEffectsDamageSkipAndExpiry(t *testing.T) {
+	withStatusConfig(t, map[StatusEffectType]StatusEffectConfig{
+		Overheat:    {Duration: 2, DamagePerTurn: 5},
+		EngineStall: {Duration: 1, SkipTurn: true},
+	})
+
+	unit := UnitStats{HP: 100}
+	ApplyStatusEffect(Balance(), &unit, *newStatusEffect(Balance(), Overheat))
+	ApplyStatusEffect(Balance(), &unit, *newStatusEffect(Balance(), EngineStall))
+
+	var log []string
+	if !TickEffects(&unit, &log, "Enemy") {
+		t.Errorf("ENGINE_STALL should skip the turn")
+	}
+	if unit.HP != 95 {
+		t.Errorf("Expected 5 OVERHEAT damage, HP is %d", unit.HP)
+	}
+	if len(unit.Effects) != 1 || unit.Effects[0].Type != Overheat {
+		t.Fatalf("ENGINE_STALL should have expired, effects: %+v", unit.Effects)
+	}
+
+	if TickEffects(&unit, &log, "Enemy") {
+		t.Errorf("Turn should not be skipped after the stall expired")
+	}
+	if len(unit.Effects) != 0 {
+		t.Errorf("OVERHEAT should have expired after 2 ticks, effects: %+v", unit.Effects)
+	}
+}
@@ -2,6 +2,7 @@ package combat
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -17,18 +18,33 @@ const (
 type UnitStats struct {
 	HP               int     `json:"hp"`
 	MaxHP            int     `json:"max_hp"`
-	Shields          int     `json:"shields"`
+	Shields          int     `json:"shields"`         // Current shield pool, absorbs damage before HP
+	MaxShields       int     `json:"max_shields"`
+	ShieldRegen      int     `json:"shield_regen"`    // Restored at the start of each of the unit's turns
+	Energy           int     `json:"energy"`          // Weapon energy, spent by ItemStats.EnergyConsume
+	MaxEnergy        int     `json:"max_energy"`
+	EnergyRegen      int     `json:"energy_regen"`    // Restored at the start of each of the unit's turns
+	Weapons          []Weapon `json:"weapons,omitempty"` // Equipped weapons; empty = unarmed attacks by damage type
 	BaseAttack       int     `json:"base_attack"`
 	TargetDefense    int     `json:"target_defense"`
 	DefenseEfficiency float64 `json:"defense_efficiency"`
 	Accuracy         int     `json:"accuracy"`
 	Evasion          int     `json:"evasion"`
+	CritChance       int     `json:"crit_chance,omitempty"` // Bonus crit chance (percentage points)
+	CritResist       int     `json:"crit_resist,omitempty"` // Subtracted from attackers' crit chance
+	CritDamage       float64 `json:"crit_damage,omitempty"` // Added to the critical damage multiplier
 	Speed            int     `json:"speed"`
 	ResonanceLevel   int     `json:"resonance_level"` // 0 = Normal, >0 = Resonant
 	ResonanceGauge   float64 `json:"resonance_gauge"` // 0-100
 	IsResonanceActive bool    `json:"is_resonance_active"`
+	ResonanceTurns    int     `json:"resonance_turns,omitempty"`    // Turns of Resonance Mode left
+	ResonanceCooldown int     `json:"resonance_cooldown,omitempty"` // Turns until Resonance can be activated again
 	IsVehicle        bool    `json:"is_vehicle"`      // To handle Scale Suppression
+	ArmorType        DamageType `json:"armor_type,omitempty"` // Defender column of the type matrix; "" = neutral
 	IsPlayer         bool    `json:"is_player"`       // To handle scripted events
+	O2               float64 `json:"o2,omitempty"`     // Pilot life support; drains each turn while on foot
+	MaxO2            float64 `json:"max_o2,omitempty"` // 0 = O2 not tracked for this unit
+	Effects          []StatusEffect `json:"effects,omitempty"` // Active status effects
 }
 
 type CombatResult struct {
@@ -36,6 +52,12 @@ type CombatResult struct {
 	IsCritical   bool           `json:"is_critical"`
 	IsMiss       bool           `json:"is_miss"`
 	AppliedEffect *StatusEffect `json:"applied_effect,omitempty"`
+	ShieldDamage  int           `json:"shield_damage,omitempty"` // Shield points stripped by this hit
+	Seed          int64         `json:"seed"` // Seed of the engine that rolled this result (for replays)
+	Splash        []SplashHit   `json:"splash,omitempty"` // Area damage to the target's squadmates (Explosive)
+	TypeMultiplier float64      `json:"type_multiplier,omitempty"` // Attack type vs defender armor type
+	Odds           HitOdds      `json:"odds"`                      // Probabilities this attack was rolled against
+	BalanceVersion string       `json:"balance_version,omitempty"` // Balance config that produced this result
 }
 
 type StatusEffectType string
@@ -46,42 +68,155 @@ const (
 	EngineStall StatusEffectType = "ENGINE_STALL"
 )
 
+// TypeMultipliers is the attacker damage type x defender armor type matrix used when the balance
+// config does not define damage_types.matrix. Missing entries (and unarmored defenders) are 1.0.
 var TypeMultipliers = map[DamageType]map[DamageType]float64{
 	Kinetic: {
 		Kinetic:   1.0,
 		Energy:    1.5,
 		Explosive: 0.5,
+		Void:      0.75,
 	},
 	Energy: {
 		Kinetic:   0.5,
 		Energy:    1.0,
 		Explosive: 1.5,
+		Void:      0.75,
 	},
 	Explosive: {
 		Kinetic:   1.5,
 		Energy:    0.5,
 		Explosive: 1.0,
+		Void:      0.75,
 	},
+	Void: {
+		Kinetic:   1.25,
+		Energy:    1.25,
+		Explosive: 1.25,
+		Void:      0.5,
+	},
+}
+
+// TypeMultiplier returns the damage multiplier of an attack type against an armor type
+func TypeMultiplier(attack DamageType, armor DamageType) float64 {
+	return typeMultiplier(Balance(), attack, armor)
+}
+
+func typeMultiplier(cfg *BalanceConfig, attack DamageType, armor DamageType) float64 {
+	matrix := cfg.DamageTypes.Matrix
+	if matrix == nil {
+		matrix = TypeMultipliers
+	}
+	if m, ok := matrix[attack][armor]; ok {
+		return m
+	}
+	return 1.0
+}
+
+// defensePierce returns the share of the defender's defense an attack type ignores
+func defensePierce(cfg *BalanceConfig, attack DamageType) float64 {
+	pierce := cfg.DamageTypes.DefensePierce
+	if pierce == nil {
+		pierce = map[DamageType]float64{Void: 0.3}
+	}
+	return pierce[attack]
+}
+
+// ArmorForClass returns the armor type configured for a vehicle or enemy class ("" = unarmored)
+func ArmorForClass(class string) DamageType {
+	return Balance().DamageTypes.ClassArmor[class]
 }
 
 type StatusEffect struct {
 	Type     StatusEffectType `json:"type"`
 	Duration int              `json:"duration"` // in turns
+	Stacks   int              `json:"stacks,omitempty"`
+	Stat     string           `json:"stat,omitempty"`     // Script buffs: attack | defense | speed | evasion
+	Modifier float64          `json:"modifier,omitempty"` // Script buffs: multiplier delta per stack
 }
 
-type Engine struct{}
+// Engine rolls all combat randomness from its own seeded source, so a fight
+// can be replayed exactly from its seed and the same sequence of inputs.
+type Engine struct {
+	mu   sync.Mutex
+	seed int64
+	src  *countingSource
+	rng  *rand.Rand
+}
+
+// countingSource counts the values drawn so a persisted fight can be resumed mid-stream
+type countingSource struct {
+	src   rand.Source
+	draws int64
+}
+
+func (c *countingSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.draws = 0
+}
 
+// NewEngine creates an engine seeded from the current time
 func NewEngine() *Engine {
-	return &Engine{}
+	return NewEngineWithSeed(time.Now().UnixNano())
+}
+
+// NewEngineWithSeed creates an engine with a fixed seed (replays, QA, tests)
+func NewEngineWithSeed(seed int64) *Engine {
+	src := &countingSource{src: rand.NewSource(seed)}
+	return &Engine{
+		seed: seed,
+		src:  src,
+		rng:  rand.New(src),
+	}
+}
+
+// RestoreEngine recreates an engine at the point where a persisted fight left off
+func RestoreEngine(seed int64, draws int64) *Engine {
+	e := NewEngineWithSeed(seed)
+	for i := int64(0); i < draws; i++ {
+		e.src.Int63()
+	}
+	return e
+}
+
+// Seed returns the seed the engine was created with
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
+// Draws returns how many values the engine has drawn from its source since it was seeded
+func (e *Engine) Draws() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.src.draws
+}
+
+// roll returns a value in [0, n) from the engine's source
+func (e *Engine) roll(n int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.rng.Intn(n)
 }
 
 func (e *Engine) CalculateDamage(attacker UnitStats, defender UnitStats, dmgType DamageType) CombatResult {
-	rand.Seed(time.Now().UnixNano())
+	return e.calculateDamage(CurrentBalance(), attacker, defender, dmgType)
+}
+
+// calculateDamage is CalculateDamage against one balance snapshot, so a reload mid-attack cannot mix two configs
+func (e *Engine) calculateDamage(balance *BalanceSnapshot, attacker UnitStats, defender UnitStats, dmgType DamageType) CombatResult {
+	cfg := &balance.Config
+
+	odds := computeOdds(cfg, attacker, defender)
 
 	// 1. Check for Miss
-	missChance := defender.Evasion - (attacker.Accuracy / 10)
-	if missChance > 0 && rand.Intn(100) < missChance {
-		return CombatResult{FinalDamage: 0, IsMiss: true}
+	missChance := 100 - odds.HitChance
+	if missChance > 0 && e.roll(100) < missChance {
+		return CombatResult{FinalDamage: 0, IsMiss: true, Seed: e.seed, Odds: odds, BalanceVersion: balance.Version}
 	}
 
 	// 2. Calculate Base Damage & Apply Damage Matrix
@@ -92,11 +227,11 @@ func (e *Engine) CalculateDamage(attacker UnitStats, defender UnitStats, dmgType
 		// Human attacking Vehicle
 		if attacker.IsResonanceActive {
 			// Resonance bypasses suppression and adds bonus
-			resonanceBonus := 1.0 + (float64(attacker.ResonanceLevel) * GlobalBalance.Resonance.ResonanceDamageMultiplier)
+			resonanceBonus := 1.0 + (float64(attacker.ResonanceLevel) * cfg.Resonance.ResonanceDamageMultiplier)
 			baseDmg *= resonanceBonus
 		} else {
 			// Normal human vs Vehicle: 90% damage reduction
-			baseDmg *= GlobalBalance.ScaleSuppression.HumanVsMechDamageReduction
+			baseDmg *= cfg.ScaleSuppression.HumanVsMechDamageReduction
 		}
 	} else if attacker.IsVehicle && !defender.IsVehicle {
 		// Vehicle attacking Human
@@ -104,55 +239,49 @@ func (e *Engine) CalculateDamage(attacker UnitStats, defender UnitStats, dmgType
 			// Resonant human can partially dodge/deflect vehicle-scale damage
 			if attacker.IsResonanceActive {
 				// If both are resonant, the mech's power is harder to dodge
-				baseDmg *= GlobalBalance.ScaleSuppression.ResonantHumanVsMechDamageMultiplier 
+				baseDmg *= cfg.ScaleSuppression.ResonantHumanVsMechDamageMultiplier 
 			} else {
-				baseDmg *= GlobalBalance.ScaleSuppression.ResonantHumanDeflectionRate
+				baseDmg *= cfg.ScaleSuppression.ResonantHumanDeflectionRate
 			}
 		} else {
 			// Vehicle vs normal human: 300% damage (Overkill)
-			baseDmg *= GlobalBalance.ScaleSuppression.MechVsHumanDamageMultiplier
+			baseDmg *= cfg.ScaleSuppression.MechVsHumanDamageMultiplier
 		}
 	}
 
 	// Defense Calculation
 	defense := float64(defender.TargetDefense) * defender.DefenseEfficiency
 
-	// Damage Matrix Logic
-	switch dmgType {
-	case Energy:
-		// +20% Damage vs Shields
-		if defender.Shields > 0 {
-			baseDmg *= 1.2
-		}
-	case Void:
-		// Ignores 30% Defense
-		defense *= 0.7
-	case Kinetic:
-		// Standard (High Base - usually handled by higher base stats on Kinetic weapons)
-	}
+	// Damage Matrix Logic: some types pierce armor (Void), then attack type vs armor type.
+	// Energy's edge against shields is handled in AbsorbDamage.
+	defense *= 1 - defensePierce(cfg, dmgType)
+	multiplier := typeMultiplier(cfg, dmgType, defender.ArmorType)
 
-	finalDmg := baseDmg - defense
+	finalDmg := (baseDmg - defense) * multiplier
 	if finalDmg < 1 {
 		finalDmg = 1 // Minimum 1 damage
 	}
 
 	// 3. Check for Critical Hit
-	critChance := 5 + (attacker.Accuracy / 100)
-	isCritical := rand.Intn(100) < critChance
+	isCritical := e.roll(100) < odds.CritChance
 	if isCritical {
-		finalDmg *= 1.5
+		finalDmg *= odds.CritMultiplier
 	}
 
-	// 4. Determine Status Effect (Simplified chance)
+	// 4. Determine Status Effect
+	applyChance := cfg.StatusEffects.ApplyChance
+	if applyChance == 0 {
+		applyChance = 20 // 20% chance on normal hit when not configured
+	}
 	var effect *StatusEffect
-	if !isCritical && rand.Intn(100) < 20 { // 20% chance on normal hit
+	if !isCritical && e.roll(100) < applyChance {
 		switch dmgType {
 		case Energy:
-			effect = &StatusEffect{Type: Overheat, Duration: 2}
+			effect = newStatusEffect(cfg, Overheat)
 		case Kinetic:
-			effect = &StatusEffect{Type: ArmorBreach, Duration: 99} // Permanent
+			effect = newStatusEffect(cfg, ArmorBreach)
 		case Explosive:
-			effect = &StatusEffect{Type: EngineStall, Duration: 1}
+			effect = newStatusEffect(cfg, EngineStall)
 		}
 	}
 
@@ -161,5 +290,9 @@ func (e *Engine) CalculateDamage(attacker UnitStats, defender UnitStats, dmgType
 		IsCritical:    isCritical,
 		IsMiss:        false,
 		AppliedEffect: effect,
+		Seed:          e.seed,
+		TypeMultiplier: multiplier,
+		Odds:           odds,
+		BalanceVersion: balance.Version,
 	}
 }
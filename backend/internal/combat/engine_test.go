@@ -2,6 +2,8 @@ package combat
 
 import (
 	"testing"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
 )
 
 func TestCalculateDamage(t *testing.T) {
@@ -19,7 +21,7 @@ func TestCalculateDamage(t *testing.T) {
 
 	// Test Kinetic vs Kinetic (1.0x)
 	// Expected: (100 * 1.0) - (50 * 0.5) = 100 - 25 = 75
-	result := CalculateDamage(attacker, defender, Kinetic)
+	result := NewEngineWithSeed(1).CalculateDamage(attacker, defender, Kinetic)
 	
 	if !result.IsMiss {
 		if !result.IsCritical && result.FinalDamage != 75 {
@@ -29,6 +31,27 @@ func TestCalculateDamage(t *testing.T) {
 			t.Errorf("Expected 112 critical damage, got %d", result.FinalDamage)
 		}
 	}
+	if result.Seed != 1 {
+		t.Errorf("Expected result to carry seed 1, got %d", result.Seed)
+	}
+}
+
+func TestCalculateDamageIsReproducible(t *testing.T) {
+	attacker := UnitStats{BaseAttack: 60, Accuracy: 80}
+	defender := UnitStats{TargetDefense: 20, DefenseEfficiency: 0.5, Evasion: 30}
+
+	a := NewEngineWithSeed(1337)
+	b := NewEngineWithSeed(1337)
+	for i := 0; i < 200; i++ {
+		ra := a.CalculateDamage(attacker, defender, Energy)
+		rb := b.CalculateDamage(attacker, defender, Energy)
+		if ra.FinalDamage != rb.FinalDamage || ra.IsMiss != rb.IsMiss || ra.IsCritical != rb.IsCritical {
+			t.Fatalf("Roll %d diverged for the same seed: %+v vs %+v", i, ra, rb)
+		}
+		if (ra.AppliedEffect == nil) != (rb.AppliedEffect == nil) {
+			t.Fatalf("Roll %d applied effect diverged for the same seed", i)
+		}
+	}
 }
 
 func TestStatusEffects(t *testing.T) {
@@ -36,9 +59,10 @@ func TestStatusEffects(t *testing.T) {
 	defender := UnitStats{TargetDefense: 0, DefenseEfficiency: 0}
 
 	// We might need to run multiple times to catch the 20% chance
+	engine := NewEngineWithSeed(7)
 	foundEffect := false
 	for i := 0; i < 100; i++ {
-		result := CalculateDamage(attacker, defender, Energy)
+		result := engine.CalculateDamage(attacker, defender, Energy)
 		if result.AppliedEffect != nil && result.AppliedEffect.Type == Overheat {
 			foundEffect = true
 			break
@@ -61,3 +85,83 @@ func TestTypeMultipliers(t *testing.T) {
 		t.Errorf("Explosive vs Kinetic should be 1.5x")
 	}
 }
+
+func TestTypeMatrixAppliedToArmor(t *testing.T) {
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.StatusEffects.ApplyChance = 0
+		cfg.DamageTypes.Matrix = nil // Built-in matrix
+		cfg.DamageTypes.DefensePierce = map[DamageType]float64{Void: 0.5}
+	})
+
+	attacker := UnitStats{BaseAttack: 40, Accuracy: 0, IsVehicle: true}
+	hit := func(dmgType DamageType, armor DamageType) CombatResult {
+		defender := UnitStats{TargetDefense: 20, DefenseEfficiency: 1, IsVehicle: true, ArmorType: armor}
+		// Try seeds until the 5% crit roll misses
+		for seed := int64(1); ; seed++ {
+			res := NewEngineWithSeed(seed).CalculateDamage(attacker, defender, dmgType)
+			if !res.IsCritical {
+				return res
+			}
+		}
+	}
+
+	if res := hit(Kinetic, ""); res.FinalDamage != 20 || res.TypeMultiplier != 1.0 {
+		t.Errorf("unarmored: got %d x%v, want 20 x1", res.FinalDamage, res.TypeMultiplier)
+	}
+	if res := hit(Kinetic, Energy); res.FinalDamage != 30 {
+		t.Errorf("kinetic vs energy armor: got %d, want 30", res.FinalDamage)
+	}
+	if res := hit(Kinetic, Explosive); res.FinalDamage != 10 {
+		t.Errorf("kinetic vs explosive armor: got %d, want 10", res.FinalDamage)
+	}
+	// Void pierces half the defense, then 1.25x against conventional armor
+	if res := hit(Void, Kinetic); res.FinalDamage != 37 {
+		t.Errorf("void vs kinetic armor: got %d, want 37", res.FinalDamage)
+	}
+	if res := hit(Void, Void); res.FinalDamage != 15 {
+		t.Errorf("void vs void armor: got %d, want 15", res.FinalDamage)
+	}
+
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.DamageTypes.Matrix = map[DamageType]map[DamageType]float64{Kinetic: {Energy: 3}}
+	})
+	if res := hit(Kinetic, Energy); res.FinalDamage != 60 {
+		t.Errorf("configured matrix: got %d, want 60", res.FinalDamage)
+	}
+}
+
+func TestComputeOddsFromPilotBonuses(t *testing.T) {
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.HitModel.Attributes = map[string]HitBonus{
+			"agility": {Evasion: 0.5},
+			"luck":    {CritChance: 1},
+		}
+		cfg.HitModel.Research = map[string]HitBonus{"SHOCK_ABSORBERS": {CritResist: 10}}
+	})
+
+	service := NewService(NewEngine())
+	pilot := &game.PilotStats{
+		SyncLevel:           1,
+		CharacterAttributes: map[string]int{"AGILITY": 20, "luck": 3},
+		Metadata:            map[string]interface{}{"unlocked_research": []interface{}{"SHOCK_ABSORBERS"}},
+	}
+	player := service.MapVehicleToUnitStats(nil, nil, pilot)
+	if player.Evasion != 20 || player.CritChance != 3 || player.CritResist != 10 {
+		t.Fatalf("pilot bonuses: evasion %d crit %d resist %d", player.Evasion, player.CritChance, player.CritResist)
+	}
+
+	enemy := UnitStats{Accuracy: 80, Evasion: 5}
+	// Enemy vs pilot: 20 evasion - 80/10 = 12% miss; 5% base crit - 10 resist clamps to 0
+	if odds := ComputeOdds(enemy, player); odds.HitChance != 88 || odds.CritChance != 0 || odds.CritMultiplier != 1.5 {
+		t.Errorf("enemy odds: %+v", odds)
+	}
+	// Pilot vs enemy: 5 - 70/10 clamps to 0% miss; 5 + 0 + 3 = 8% crit
+	if odds := ComputeOdds(player, enemy); odds.HitChance != 100 || odds.CritChance != 8 {
+		t.Errorf("pilot odds: %+v", odds)
+	}
+
+	res := NewEngineWithSeed(1).CalculateDamage(player, enemy, Kinetic)
+	if res.Odds.CritChance != 8 {
+		t.Errorf("result should carry the odds it rolled against, got %+v", res.Odds)
+	}
+}
@@ -0,0 +1,68 @@
+package combat
+
+import "fmt"
+
+func o2PerTurn() float64 {
+	if d := Balance().EVA.O2PerTurn; d > 0 {
+		return d
+	}
+	return 2
+}
+
+func suffocationDamage() int {
+	if d := Balance().EVA.SuffocationDamage; d > 0 {
+		return d
+	}
+	return 10
+}
+
+// drainO2 runs at the start of the player lead's turn: a pilot on foot breathes from the suit,
+// and once O2 is gone every turn costs HP
+func (s *Service) drainO2(session *CombatSession) {
+	player := &session.PlayerStats
+	if player.IsVehicle || player.MaxO2 <= 0 || player.HP <= 0 {
+		return
+	}
+
+	if player.O2 > 0 {
+		player.O2 -= o2PerTurn()
+		if player.O2 <= 0 {
+			player.O2 = 0
+			session.Log = append(session.Log, "[SYSTEM] O2 DEPLETED. LIFE SUPPORT FAILING.")
+		}
+		return
+	}
+
+	damage := suffocationDamage()
+	player.HP -= damage
+	if player.HP < 0 {
+		player.HP = 0
+	}
+	session.Log = append(session.Log, fmt.Sprintf("[SYSTEM] SUFFOCATING: -%d HP.", damage))
+}
+
+// ejectPilot continues the battle on foot: the player lead takes its EVA stats but keeps its
+// resonance state, status effects and O2. hp > 0 overrides the suit HP.
+func (s *Service) ejectPilot(session *CombatSession, hp int) {
+	player := &session.PlayerStats
+	if session.EjectStats != nil {
+		eva := *session.EjectStats
+		eva.ResonanceGauge = player.ResonanceGauge
+		eva.IsResonanceActive = player.IsResonanceActive
+		eva.ResonanceTurns = player.ResonanceTurns
+		eva.ResonanceCooldown = player.ResonanceCooldown
+		eva.Effects = player.Effects
+		eva.O2, eva.MaxO2 = player.O2, player.MaxO2
+		*player = eva
+	} else {
+		player.HP = Balance().BaseStats.ForcedSurvivalHP
+	}
+
+	player.IsVehicle = false
+	if hp > 0 {
+		player.HP = hp
+	}
+	if player.HP > player.MaxHP {
+		player.MaxHP = player.HP
+	}
+}
@@ -0,0 +1,123 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
+)
+
+func TestMapPilotToUnitStatsFromExosuit(t *testing.T) {
+	service := NewService(NewEngine())
+	pilot := &game.PilotStats{SyncLevel: 1, CurrentO2: 60}
+
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.Progression.BaseSyncRate = 1
+		cfg.Shields.CapacityPerGeneration = 5
+	})
+
+	bare := service.MapPilotToUnitStats(nil, nil, pilot)
+	if bare.IsVehicle || bare.HP != 100 || bare.BaseAttack != 10 || bare.O2 != 60 || bare.MaxO2 != 100 {
+		t.Fatalf("bare pilot: %+v", bare)
+	}
+
+	kinetic := "KINETIC"
+	suit := &vehicle.Item{ID: uuid.New(), ItemType: vehicle.ItemTypeExosuit, Condition: vehicle.ConditionPristine,
+		Stats: vehicle.ItemStats{HP: 50, Defense: 10, Speed: 20}}
+	parts := []vehicle.Item{
+		{ID: uuid.New(), IsEquipped: true, DamageType: &kinetic, Stats: vehicle.ItemStats{Attack: 6}},
+		{ID: uuid.New(), IsEquipped: true, Stats: vehicle.ItemStats{BonusHP: 20, ShieldGeneration: 1}},
+	}
+	stats := service.MapPilotToUnitStats(suit, parts, pilot)
+	if stats.HP != 170 || stats.MaxHP != 170 || stats.TargetDefense != 15 || stats.Speed != 70 {
+		t.Errorf("suited pilot: hp %d/%d def %d speed %d", stats.HP, stats.MaxHP, stats.TargetDefense, stats.Speed)
+	}
+	if len(stats.Weapons) != 1 || stats.MaxShields != 5 {
+		t.Errorf("suit parts should arm and shield the pilot: %d weapons, %d shields", len(stats.Weapons), stats.MaxShields)
+	}
+
+	suit.Condition = vehicle.ConditionBroken
+	if broken := service.MapPilotToUnitStats(suit, parts, pilot); broken.HP != 100 || len(broken.Weapons) != 0 {
+		t.Errorf("broken suit should give nothing, got hp %d weapons %d", broken.HP, len(broken.Weapons))
+	}
+}
+
+func TestO2DrainsOnFootThenSuffocates(t *testing.T) {
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.EVA.O2PerTurn = 3
+		cfg.EVA.SuffocationDamage = 7
+	})
+
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 100, MaxHP: 100, IsPlayer: true, O2: 5, MaxO2: 100, Speed: 50}
+	enemy := UnitStats{HP: 1000, MaxHP: 1000, Speed: 10, IsVehicle: true}
+	session := service.NewSession(player, enemy, 1)
+
+	service.drainO2(session)
+	if session.PlayerStats.O2 != 2 {
+		t.Fatalf("O2 = %v, want 2", session.PlayerStats.O2)
+	}
+	service.drainO2(session)
+	service.drainO2(session)
+	if session.PlayerStats.O2 != 0 || session.PlayerStats.HP != 93 {
+		t.Errorf("expected empty O2 and one suffocation tick, got O2 %v HP %d", session.PlayerStats.O2, session.PlayerStats.HP)
+	}
+
+	// Vehicles keep their own life support
+	session.PlayerStats.IsVehicle = true
+	service.drainO2(session)
+	if session.PlayerStats.HP != 93 {
+		t.Errorf("pilot in a vehicle should not suffocate, HP %d", session.PlayerStats.HP)
+	}
+}
+
+func TestForceEjectContinuesOnFoot(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(game.ScriptEvent{Trigger: game.TriggerPlayerHPBelow, Threshold: 20, Action: game.ActionForceEject})
+	session.PlayerStats.ResonanceGauge = 80
+	session.PlayerStats.O2, session.PlayerStats.MaxO2 = 50, 100
+	session.EjectStats = &UnitStats{HP: 140, MaxHP: 140, BaseAttack: 12, Speed: 60, IsPlayer: true}
+
+	session.PlayerStats.HP = 10
+	service.handleScriptedEvents(session)
+
+	p := session.PlayerStats
+	if p.IsVehicle || p.HP != 140 || p.BaseAttack != 12 {
+		t.Fatalf("expected suit stats on foot, got vehicle=%v hp %d atk %d", p.IsVehicle, p.HP, p.BaseAttack)
+	}
+	if p.ResonanceGauge != 80 || p.O2 != 50 {
+		t.Errorf("resonance and O2 should carry over, got gauge %v O2 %v", p.ResonanceGauge, p.O2)
+	}
+	if session.Outcome != OutcomeOngoing {
+		t.Errorf("battle should continue after ejecting, got %s", session.Outcome)
+	}
+}
+
+func TestForceEjectMidTurnDropsCommandedWeapon(t *testing.T) {
+	service := NewService(NewEngine())
+	cannon := weaponItem("EXPLOSIVE", 50, 0, nil)
+	session := armedSession(service, cannon)
+	session.IsScripted = true
+	session.ScriptEvents = []game.ScriptEvent{{Trigger: game.TriggerPlayerHPBelow, Threshold: 100, Action: game.ActionForceEject}}
+	session.EjectStats = &UnitStats{HP: 80, MaxHP: 80, BaseAttack: 8, Accuracy: 100, Speed: 40, IsPlayer: true}
+	session.EnemyStats.Speed = 1000 // The enemy hits first and triggers the eject before the player fires
+
+	events, err := service.PlayCommand(session, PlayerCommand{WeaponID: &cannon.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.PlayerStats.IsVehicle {
+		t.Fatal("expected the pilot to be ejected mid-turn")
+	}
+
+	var shot *TurnEvent
+	for i := range events {
+		if events[i].Actor == SidePlayer {
+			shot = &events[i]
+		}
+	}
+	if shot == nil || shot.WeaponID != nil || shot.DamageType != Kinetic {
+		t.Errorf("expected an unarmed Kinetic attack after ejecting, got %+v", shot)
+	}
+}
@@ -1,8 +1,14 @@
 package combat
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ryudokung/Project-0/backend/internal/auth/constants"
@@ -12,22 +18,29 @@ import (
 
 type Handler struct {
 	service     *Service
-	vehicleRepo vehicle.Repository
-	gameRepo    game.Repository
+	repo        Repository
+	vehicleRepo    vehicle.Repository
+	vehicleUseCase vehicle.UseCase
+	gameRepo       game.Repository
+	encounters     EncounterProvider
 }
 
-func NewHandler(service *Service, vehicleRepo vehicle.Repository, gameRepo game.Repository) *Handler {
+func NewHandler(service *Service, repo Repository, vehicleRepo vehicle.Repository, vehicleUseCase vehicle.UseCase, gameRepo game.Repository, encounters EncounterProvider) *Handler {
 	return &Handler{
-		service:     service,
-		vehicleRepo: vehicleRepo,
-		gameRepo:    gameRepo,
+		service:        service,
+		repo:           repo,
+		vehicleRepo:    vehicleRepo,
+		vehicleUseCase: vehicleUseCase,
+		gameRepo:       gameRepo,
+		encounters:     encounters,
 	}
 }
 
 type BattleRequest struct {
 	AttackerVehicleID string `json:"attacker_vehicle_id"`
 	DefenderVehicleID string `json:"defender_vehicle_id"`
-	DamageType        string `json:"damage_type"`
+	WeaponID          string `json:"weapon_id"`   // Equipped weapon to fire (armed attackers)
+	DamageType        string `json:"damage_type"` // Unarmed attacks only (e.g. Pilot Only mode)
 }
 
 func (h *Handler) SimulateAttack(w http.ResponseWriter, r *http.Request) {
@@ -62,6 +75,7 @@ func (h *Handler) SimulateAttack(w http.ResponseWriter, r *http.Request) {
 
 	// 1. Fetch Vehicles
 	var attacker *vehicle.Vehicle
+	var attackerSuit *vehicle.Item
 	var attackerItems []vehicle.Item
 	var attackerPilot *game.PilotStats
 
@@ -86,8 +100,17 @@ func (h *Handler) SimulateAttack(w http.ResponseWriter, r *http.Request) {
 		attackerItems, _ = h.vehicleRepo.GetItemsByParentItemID(r.Context(), attackerUUID)
 		attackerPilot, _ = h.gameRepo.GetActivePilotStats(attacker.OwnerID)
 	} else {
-		// Pilot Only Mode
-		attackerPilot, _ = h.gameRepo.GetActivePilotStats(userID)
+		// Pilot Only Mode: on foot in the pilot's exosuit, as in a battle
+		var ok bool
+		attackerPilot, err = h.gameRepo.GetActivePilotStats(userID)
+		if err != nil {
+			http.Error(w, "Error fetching pilot stats: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		attackerSuit, attackerItems, ok = h.loadExosuit(w, r, userID, attackerPilot)
+		if !ok {
+			return
+		}
 	}
 
 	defender, err := h.vehicleRepo.GetByID(r.Context(), defenderUUID)
@@ -113,18 +136,39 @@ func (h *Handler) SimulateAttack(w http.ResponseWriter, r *http.Request) {
 	defenderPilot, _ := h.gameRepo.GetActivePilotStats(defender.OwnerID)
 
 	// 4. Map to Combat Stats
-	attackerStats := h.service.MapVehicleToUnitStats(attacker, attackerItems, attackerPilot)
+	attackerStats := h.service.MapPilotToUnitStats(attackerSuit, attackerItems, attackerPilot)
+	if attacker != nil {
+		attackerStats = h.service.MapVehicleToUnitStats(attacker, attackerItems, attackerPilot)
+	}
 	defenderStats := h.service.MapVehicleToUnitStats(defender, defenderItems, defenderPilot)
 
-	// 5. Create Combat Session
-	session := &CombatSession{
-		PlayerStats: attackerStats,
-		EnemyStats:  defenderStats,
-		IsScripted:  false, // Standard combat is not scripted
+	// 5. Create Combat Session (own seed so the exchange can be replayed from result.seed)
+	session := h.service.NewSession(attackerStats, defenderStats, time.Now().UnixNano())
+	session.IsScripted = false // Standard combat is not scripted
+	if attacker != nil {
+		session.VehicleID = &attackerUUID
+	}
+	carrier := session.VehicleID
+	if attackerSuit != nil && attacker == nil {
+		session.ExosuitID = &attackerSuit.ID
+		carrier = session.ExosuitID
 	}
 
-	// 6. Execute Attack
-	result := h.service.ExecuteAttack(session, DamageType(req.DamageType))
+	// 6. Execute Attack with a weapon equipped on the attacker, validated like Act
+	cmd := PlayerCommand{DamageType: DamageType(req.DamageType)}
+	if len(attackerStats.Weapons) > 0 {
+		weaponID, ok := h.equippedWeapon(w, r, req.WeaponID, carrier)
+		if !ok {
+			return
+		}
+		cmd.WeaponID = weaponID
+		cmd.DamageType = ""
+	}
+	result, err := h.service.ExecuteAttack(session, cmd)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// 7. Persist State (Anti-Cheat)
 	newHP := session.EnemyStats.HP
@@ -135,29 +179,21 @@ func (h *Handler) SimulateAttack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 7. Apply Durability Loss
-	// Attacker loses 1 durability on a random item (using first item for simplicity)
-	if len(attackerItems) > 0 {
-		item := attackerItems[0]
-		newDurability := item.Durability - 1
-		if newDurability < 0 { newDurability = 0 }
-		
-		condition := item.Condition
-		if newDurability == 0 {
-			condition = vehicle.ConditionBroken
-		} else if newDurability < item.MaxDurability/4 {
-			condition = vehicle.ConditionCritical
-		} else if newDurability < item.MaxDurability/2 {
-			condition = vehicle.ConditionDamaged
-		} else if newDurability < item.MaxDurability {
-			condition = vehicle.ConditionWorn
+	// 8. Apply Durability Loss (DDS), the same conversion battles use
+	for vehicleID, damage := range h.service.DurabilityDamage(session) {
+		if _, err := h.vehicleUseCase.ApplyCombatDamage(r.Context(), vehicleID, damage); err != nil {
+			http.Error(w, "Failed to apply durability damage: "+err.Error(), http.StatusInternalServerError)
+			return
 		}
-
-		h.vehicleRepo.UpdateDurability(r.Context(), item.ID, newDurability, condition)
+	}
+	if _, err := h.vehicleUseCase.ApplyCombatDamage(r.Context(), defenderUUID, h.service.EnemyVehicleDurabilityDamage(session)); err != nil {
+		http.Error(w, "Failed to apply durability damage: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	// Update local stats for response
-	defenderStats.HP = newHP
+	attackerStats = session.PlayerStats
+	defenderStats = session.EnemyStats
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -166,3 +202,633 @@ func (h *Handler) SimulateAttack(w http.ResponseWriter, r *http.Request) {
 		"result":         result,
 	})
 }
+
+// EncounterInfo is what the combat module needs to know about an exploration encounter to start its fight
+type EncounterInfo struct {
+	EncounterID  uuid.UUID
+	ExpeditionID uuid.UUID
+	UserID       uuid.UUID
+	VehicleID    *uuid.UUID
+	EnemyID      uuid.UUID
+	Enemy        game.EnemyBlueprint
+	EnemyHP      int // Lead enemy's current HP; 0 means full
+	Loot         *game.LootDrop // Granted if the fight is won
+	EnemyCount   int // Units spawned from Enemy; 0 means one
+	Hazard       *game.HazardEffect // The node's hazard after the pilot's counters; nil when clear
+	IsScripted   bool
+	ScriptEvents []game.ScriptEvent
+}
+
+// EncounterProvider resolves COMBAT/BOSS encounters (implemented by the exploration service)
+type EncounterProvider interface {
+	GetEncounterForCombat(ctx context.Context, encounterID uuid.UUID) (*EncounterInfo, error)
+	RecordBattleOutcome(ctx context.Context, enemyID uuid.UUID, hp int, outcome BattleOutcome) error
+}
+
+type StartBattleRequest struct {
+	EncounterID     string   `json:"encounter_id"`
+	SquadVehicleIDs []string `json:"squad_vehicle_ids,omitempty"` // Extra vehicles from the player's hangar flying as wingmen
+}
+
+type BattleActionRequest struct {
+	SessionID  string `json:"session_id"`
+	WeaponID   string `json:"weapon_id"`   // Equipped weapon item to fire (armed vehicles)
+	DamageType string `json:"damage_type"` // Unarmed attacks only (e.g. Pilot Only mode)
+	Target     int    `json:"target"`      // Enemy index: 0 = lead, 1..n = enemy squad
+}
+
+// StartBattle opens (or resumes) the persisted combat session for an exploration encounter
+func (h *Handler) StartBattle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value(constants.UserIDKey).(uuid.UUID)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req StartBattleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	encounterID, err := uuid.Parse(req.EncounterID)
+	if err != nil {
+		http.Error(w, "Invalid encounter ID format", http.StatusBadRequest)
+		return
+	}
+
+	// 1. Resume the existing fight for this encounter (page reloads, reconnects)
+	existing, err := h.repo.GetSessionByEncounterID(r.Context(), encounterID)
+	if err != nil {
+		http.Error(w, "Error fetching combat session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		if existing.UserID != userID {
+			http.Error(w, "You do not own this combat session", http.StatusForbidden)
+			return
+		}
+		writeSession(w, existing, nil)
+		return
+	}
+
+	// 2. Resolve the encounter's enemy and script
+	info, err := h.encounters.GetEncounterForCombat(r.Context(), encounterID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if info.UserID != userID {
+		http.Error(w, "You do not own this encounter", http.StatusForbidden)
+		return
+	}
+
+	// 3. Build player stats from the expedition's vehicle (nil = Pilot Only mode)
+	var playerVehicle *vehicle.Vehicle
+	var playerItems []vehicle.Item
+	if info.VehicleID != nil {
+		playerVehicle, err = h.vehicleRepo.GetByID(r.Context(), *info.VehicleID)
+		if err != nil {
+			http.Error(w, "Error fetching vehicle: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if playerVehicle != nil {
+			playerItems, _ = h.vehicleRepo.GetItemsByParentItemID(r.Context(), playerVehicle.ID)
+		}
+	}
+	pilot, err := h.gameRepo.GetActivePilotStats(userID)
+	if err != nil {
+		http.Error(w, "Error fetching pilot stats: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The pilot's exosuit: their stats on foot, from the start or after a force_eject
+	suit, suitParts, ok := h.loadExosuit(w, r, userID, pilot)
+	if !ok {
+		return
+	}
+	evaStats := h.service.MapPilotToUnitStats(suit, suitParts, pilot)
+
+	playerStats := evaStats
+	if playerVehicle != nil {
+		playerStats = h.service.MapVehicleToUnitStats(playerVehicle, playerItems, pilot)
+	}
+	enemyStats := h.service.MapEnemyBlueprintToUnitStats(info.Enemy)
+	leadStats := enemyStats
+	if info.EnemyHP > 0 && info.EnemyHP < leadStats.MaxHP {
+		leadStats.HP = info.EnemyHP // A wounded enemy from an earlier fight keeps its damage
+	}
+
+	// 4. Wingmen must be the player's own vehicles, each flying once
+	if len(req.SquadVehicleIDs)+1 > MaxSquadSize() {
+		http.Error(w, fmt.Sprintf("Squad too large (max %d units)", MaxSquadSize()), http.StatusBadRequest)
+		return
+	}
+	var squadIDs []uuid.UUID
+	var squadStats []UnitStats
+	seen := map[uuid.UUID]bool{}
+	if info.VehicleID != nil {
+		seen[*info.VehicleID] = true
+	}
+	for _, idStr := range req.SquadVehicleIDs {
+		vehicleID, err := uuid.Parse(idStr)
+		if err != nil {
+			http.Error(w, "Invalid squad vehicle ID format", http.StatusBadRequest)
+			return
+		}
+		if seen[vehicleID] {
+			http.Error(w, "Vehicle already in the squad", http.StatusBadRequest)
+			return
+		}
+		seen[vehicleID] = true
+
+		wingman, err := h.vehicleRepo.GetByID(r.Context(), vehicleID)
+		if err != nil {
+			http.Error(w, "Error fetching vehicle: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if wingman == nil || wingman.OwnerID != userID {
+			http.Error(w, "You do not own this squad vehicle", http.StatusForbidden)
+			return
+		}
+		wingmanItems, _ := h.vehicleRepo.GetItemsByParentItemID(r.Context(), wingman.ID)
+		squadIDs = append(squadIDs, vehicleID)
+		squadStats = append(squadStats, h.service.MapVehicleToUnitStats(wingman, wingmanItems, nil)) // No pilot: wingmen do not build resonance
+	}
+
+	// 5. Create and persist the session
+	session := h.service.NewSession(playerStats, leadStats, time.Now().UnixNano())
+	for _, stats := range squadStats {
+		h.service.AddUnit(session, SidePlayer, stats)
+	}
+	session.SquadVehicleIDs = squadIDs
+	for i := 1; i < info.EnemyCount && i < MaxSquadSize(); i++ {
+		h.service.AddUnit(session, SideEnemy, enemyStats)
+	}
+	session.ID = uuid.New()
+	session.UserID = userID
+	session.EncounterID = &info.EncounterID
+	session.EnemyID = &info.EnemyID
+	session.VehicleID = info.VehicleID
+	if suit != nil {
+		session.ExosuitID = &suit.ID
+	}
+	if playerVehicle != nil {
+		session.EjectStats = &evaStats
+	}
+	session.IsScripted = info.IsScripted
+	session.ScriptEvents = info.ScriptEvents
+	session.Loot = info.Loot
+	ApplyHazard(session, info.Hazard)
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+
+	if err := h.repo.CreateSession(r.Context(), session); err != nil {
+		http.Error(w, "Failed to create combat session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeSession(w, session, nil)
+}
+
+// Act plays one turn of a persisted battle with the player's chosen weapon (or damage type when unarmed)
+func (h *Handler) Act(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BattleActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	session, ok := h.loadOwnedSession(w, r, req.SessionID)
+	if !ok {
+		return
+	}
+	if session.Outcome != OutcomeOngoing {
+		// A finished battle whose payout failed part-way is retried here; the finished steps are skipped
+		if session.RewardsGranted {
+			http.Error(w, "Battle is already over", http.StatusBadRequest)
+			return
+		}
+		if err := h.finalizeBattle(r, session); err != nil {
+			writeFinalizeError(w, err)
+			return
+		}
+		writeSession(w, session, nil)
+		return
+	}
+
+	if req.Target < 0 || req.Target >= session.UnitCount(SideEnemy) {
+		http.Error(w, "Invalid target", http.StatusBadRequest)
+		return
+	}
+
+	cmd := PlayerCommand{DamageType: DamageType(req.DamageType), Target: req.Target}
+
+	// Armed units attack with an equipped weapon; the damage type comes from the item
+	if len(session.PlayerStats.Weapons) > 0 {
+		// On foot the weapon hangs off the exosuit instead of the vehicle
+		carrier := session.VehicleID
+		if !session.PlayerStats.IsVehicle {
+			carrier = session.ExosuitID
+		}
+		weaponID, ok := h.equippedWeapon(w, r, req.WeaponID, carrier)
+		if !ok {
+			return
+		}
+		cmd.WeaponID = weaponID
+		cmd.DamageType = ""
+	} else if cmd.DamageType == "" {
+		cmd.DamageType = Kinetic
+	}
+
+	events, err := h.service.PlayCommand(session, cmd)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.saveSession(r, session); err != nil {
+		writeSaveError(w, err)
+		return
+	}
+
+	// Battle just finished: keep a record, wear down the vehicles and pay out
+	if session.Outcome != OutcomeOngoing {
+		if err := h.finalizeBattle(r, session); err != nil {
+			writeFinalizeError(w, err)
+			return
+		}
+	}
+
+	writeSession(w, session, events)
+}
+
+// UseResonance activates Resonance Mode in a persisted battle if the gauge is full
+func (h *Handler) UseResonance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BattleActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	session, ok := h.loadOwnedSession(w, r, req.SessionID)
+	if !ok {
+		return
+	}
+	if session.Outcome != OutcomeOngoing {
+		http.Error(w, "Battle is already over", http.StatusBadRequest)
+		return
+	}
+	if !h.service.ActivateResonance(session) {
+		http.Error(w, "Resonance is not ready: the gauge must be full and off cooldown", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.saveSession(r, session); err != nil {
+		writeSaveError(w, err)
+		return
+	}
+
+	writeSession(w, session, nil)
+}
+
+// GetBattleState returns a persisted battle by session_id or encounter_id
+func (h *Handler) GetBattleState(w http.ResponseWriter, r *http.Request) {
+	if encounterIDStr := r.URL.Query().Get("encounter_id"); encounterIDStr != "" {
+		userID, ok := r.Context().Value(constants.UserIDKey).(uuid.UUID)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		encounterID, err := uuid.Parse(encounterIDStr)
+		if err != nil {
+			http.Error(w, "Invalid encounter ID format", http.StatusBadRequest)
+			return
+		}
+		session, err := h.repo.GetSessionByEncounterID(r.Context(), encounterID)
+		if err != nil {
+			http.Error(w, "Error fetching combat session: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if session == nil {
+			http.Error(w, "Combat session not found", http.StatusNotFound)
+			return
+		}
+		if session.UserID != userID {
+			http.Error(w, "You do not own this combat session", http.StatusForbidden)
+			return
+		}
+		writeSession(w, session, nil)
+		return
+	}
+
+	session, ok := h.loadOwnedSession(w, r, r.URL.Query().Get("session_id"))
+	if !ok {
+		return
+	}
+	writeSession(w, session, nil)
+}
+
+// ListBattleLogs returns the user's most recent battle records (?limit=, default 20)
+func (h *Handler) ListBattleLogs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(constants.UserIDKey).(uuid.UUID)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 || n > 100 {
+			http.Error(w, "Invalid limit (1-100)", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	records, err := h.repo.GetBattleRecordsByUserID(r.Context(), userID, limit)
+	if err != nil {
+		http.Error(w, "Error fetching battle logs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"logs": records,
+	})
+}
+
+type SaveBattleLogRequest struct {
+	LogID string `json:"log_id"`
+}
+
+// SaveBattleLog marks a battle record permanent so the janitor keeps it
+func (h *Handler) SaveBattleLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value(constants.UserIDKey).(uuid.UUID)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SaveBattleLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	logID, err := uuid.Parse(req.LogID)
+	if err != nil {
+		http.Error(w, "Invalid log ID format", http.StatusBadRequest)
+		return
+	}
+
+	record, err := h.repo.GetBattleRecordByID(r.Context(), logID)
+	if err != nil {
+		http.Error(w, "Error fetching battle log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if record == nil {
+		http.Error(w, "Battle log not found", http.StatusNotFound)
+		return
+	}
+	if record.UserID != userID {
+		http.Error(w, "You do not own this battle log", http.StatusForbidden)
+		return
+	}
+
+	if !record.IsPermanent {
+		if err := h.repo.MarkBattleRecordPermanent(r.Context(), logID); err != nil {
+			http.Error(w, "Failed to save battle log", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "saved",
+		"log_id": logID,
+	})
+}
+
+// loadOwnedSession fetches a session, checks ownership and reattaches its engine. It writes the error response itself.
+func (h *Handler) loadOwnedSession(w http.ResponseWriter, r *http.Request, sessionIDStr string) (*CombatSession, bool) {
+	userID, ok := r.Context().Value(constants.UserIDKey).(uuid.UUID)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		http.Error(w, "Invalid session ID format", http.StatusBadRequest)
+		return nil, false
+	}
+
+	session, err := h.repo.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Error fetching combat session: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if session == nil {
+		http.Error(w, "Combat session not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	// Ownership Check (Anti-Cheat)
+	if session.UserID != userID {
+		http.Error(w, "You do not own this combat session", http.StatusForbidden)
+		return nil, false
+	}
+
+	h.service.Resume(session)
+	return session, true
+}
+
+// loadExosuit fetches the pilot's equipped exosuit and the parts on it; a missing or foreign suit leaves the
+// pilot bare. It writes the error response itself.
+func (h *Handler) loadExosuit(w http.ResponseWriter, r *http.Request, userID uuid.UUID, pilot *game.PilotStats) (*vehicle.Item, []vehicle.Item, bool) {
+	if pilot == nil || pilot.EquippedExosuitID == nil {
+		return nil, nil, true
+	}
+	suit, err := h.vehicleRepo.GetItemByID(r.Context(), *pilot.EquippedExosuitID)
+	if err != nil {
+		http.Error(w, "Error fetching exosuit: "+err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if suit == nil || suit.OwnerID != userID {
+		return nil, nil, true
+	}
+	parts, _ := h.vehicleRepo.GetItemsByParentItemID(r.Context(), suit.ID)
+	return suit, parts, true
+}
+
+// equippedWeapon parses a weapon_id and checks the item is equipped on carrier (the attacking vehicle or exosuit)
+func (h *Handler) equippedWeapon(w http.ResponseWriter, r *http.Request, weaponIDStr string, carrier *uuid.UUID) (*uuid.UUID, bool) {
+	weaponID, err := uuid.Parse(weaponIDStr)
+	if err != nil {
+		http.Error(w, "A valid weapon_id is required", http.StatusBadRequest)
+		return nil, false
+	}
+	item, err := h.vehicleRepo.GetItemByID(r.Context(), weaponID)
+	if err != nil {
+		http.Error(w, "Error fetching weapon: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if item == nil || !item.IsEquipped || item.ParentItemID == nil || carrier == nil || *item.ParentItemID != *carrier {
+		http.Error(w, "Weapon is not equipped on the attacking unit", http.StatusBadRequest)
+		return nil, false
+	}
+	return &weaponID, true
+}
+
+func (h *Handler) saveSession(r *http.Request, session *CombatSession) error {
+	h.service.Snapshot(session)
+	session.UpdatedAt = time.Now()
+	return h.repo.UpdateSession(r.Context(), session)
+}
+
+// finalizeBattle keeps a record in combat_logs, records the outcome on the encounter, wears down the
+// vehicles (DDS) and hands the resonance gauge, Stress and any won loot to the pilot. Every step is
+// claimed on the session with a version-checked save before it is applied: a concurrent request (a
+// double-click) gets ErrSessionConflict instead of paying out twice, and a retry after a partial failure
+// finishes the grant without repeating it. A crash between claim and apply drops that step rather than
+// risking a double payout.
+func (h *Handler) finalizeBattle(r *http.Request, session *CombatSession) error {
+	step := func(key string, apply func() error) error {
+		if session.Granted(key) {
+			return nil
+		}
+		session.MarkGranted(key)
+		if err := h.saveSession(r, session); err != nil {
+			return err
+		}
+		if err := apply(); err != nil {
+			// Release the claim so the retry applies the step
+			session.UnmarkGranted(key)
+			if saveErr := h.saveSession(r, session); saveErr != nil {
+				log.Printf("Combat: could not release end-of-battle step %s of session %s: %v", key, session.ID, saveErr)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := step("battle_record", func() error {
+		if err := h.repo.CreateBattleRecord(r.Context(), h.service.NewBattleRecord(session)); err != nil {
+			return fmt.Errorf("failed to save battle record: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if session.EncounterID != nil && session.EnemyID != nil && h.encounters != nil {
+		if err := step("battle_outcome", func() error {
+			if err := h.encounters.RecordBattleOutcome(r.Context(), *session.EnemyID, session.EnemyStats.HP, session.Outcome); err != nil {
+				return fmt.Errorf("failed to record battle outcome: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	for vehicleID, damage := range h.service.DurabilityDamage(session) {
+		vehicleID, damage := vehicleID, damage
+		if err := step("durability:"+vehicleID.String(), func() error {
+			if _, err := h.vehicleUseCase.ApplyCombatDamage(r.Context(), vehicleID, damage); err != nil {
+				return fmt.Errorf("failed to apply durability damage: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	loot := session.Loot
+	if session.Outcome != OutcomeVictory {
+		loot = nil
+	}
+	if session.PlayerStats.IsPlayer || loot != nil {
+		pilot, err := h.gameRepo.GetActivePilotStats(session.UserID)
+		if err != nil {
+			return fmt.Errorf("error fetching pilot stats: %w", err)
+		}
+		if pilot != nil {
+			if err := step("pilot", func() error {
+				if session.PlayerStats.IsPlayer {
+					h.service.ApplyPilotResults(session, pilot)
+				}
+				if loot != nil {
+					loot.ApplyCurrencies(pilot)
+				}
+				if err := h.gameRepo.UpdatePilotStats(pilot); err != nil {
+					return fmt.Errorf("failed to update pilot stats: %w", err)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if loot != nil {
+				for i, li := range loot.Items {
+					li := li
+					if err := step(fmt.Sprintf("loot:%d", i), func() error {
+						if err := h.vehicleRepo.CreateItem(r.Context(), li.NewItem(session.UserID, &pilot.CharacterID)); err != nil {
+							return fmt.Errorf("failed to grant loot: %w", err)
+						}
+						return nil
+					}); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	session.RewardsGranted = true
+	return h.saveSession(r, session)
+}
+
+// writeFinalizeError reports a failed payout; the battle is saved and Act retries the remaining steps
+func writeFinalizeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrSessionConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error()+" (retry to finish the battle rewards)", http.StatusInternalServerError)
+}
+
+// writeSaveError answers 409 when another request saved the session first, so the client can reload it
+func writeSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrSessionConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, "Failed to save combat session", http.StatusInternalServerError)
+}
+
+func writeSession(w http.ResponseWriter, session *CombatSession, events []TurnEvent) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session": session,
+		"events":  events,
+		"odds":    session.PlayerOdds(), // Hit/crit chances against each standing enemy, before attacking
+	})
+}
@@ -0,0 +1,80 @@
+package combat
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// ApplyHazard puts an environmental hazard over the fight: weapons of disabled damage types go offline on
+// every unit (including the ejection suit) and damage multipliers apply to both sides. nil leaves the fight clear.
+func ApplyHazard(session *CombatSession, hazard *game.HazardEffect) {
+	session.Hazard = hazard
+	if hazard == nil {
+		return
+	}
+
+	units := []*UnitStats{&session.PlayerStats, &session.EnemyStats}
+	for i := range session.PlayerSquad {
+		units = append(units, &session.PlayerSquad[i])
+	}
+	for i := range session.EnemySquad {
+		units = append(units, &session.EnemySquad[i])
+	}
+	if session.EjectStats != nil {
+		units = append(units, session.EjectStats)
+	}
+	for _, unit := range units {
+		for i := range unit.Weapons {
+			unit.Weapons[i].Disabled = session.DamageTypeDisabled(unit.Weapons[i].DamageType)
+		}
+	}
+
+	session.Log = append(session.Log, fmt.Sprintf("[HAZARD] %s over the battlefield.", hazard.Name))
+	for _, t := range hazard.DisabledDamageTypes {
+		session.Log = append(session.Log, fmt.Sprintf("[HAZARD] %s systems offline.", t))
+	}
+}
+
+// DamageTypeDisabled reports whether the fight's hazard knocks out a damage type
+func (c *CombatSession) DamageTypeDisabled(t DamageType) bool {
+	if c.Hazard == nil {
+		return false
+	}
+	for _, d := range c.Hazard.DisabledDamageTypes {
+		if DamageType(d) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// fallbackTypes is the order usableType tries when a damage type is disabled
+var fallbackTypes = []DamageType{Kinetic, Energy, Explosive, Void}
+
+// usableType falls back to the first enabled damage type (Kinetic unless the hazard took it out too)
+// for unarmed attacks of a disabled damage type
+func (c *CombatSession) usableType(t DamageType) DamageType {
+	if !c.DamageTypeDisabled(t) {
+		return t
+	}
+	for _, f := range fallbackTypes {
+		if !c.DamageTypeDisabled(f) {
+			return f
+		}
+	}
+	return t
+}
+
+// hazardDamage applies the hazard's multiplier for the damage type
+func (c *CombatSession) hazardDamage(damage int, t DamageType) int {
+	if c.Hazard == nil {
+		return damage
+	}
+	m, ok := c.Hazard.DamageMultipliers[string(t)]
+	if !ok {
+		return damage
+	}
+	return int(math.Round(float64(damage) * m))
+}
@@ -0,0 +1,80 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+func TestHazardDisablesDamageTypes(t *testing.T) {
+	service := NewService(NewEngine())
+	beam := weaponItem("ENERGY", 40, 10, nil)
+	gun := weaponItem("KINETIC", 5, 0, nil)
+	session := armedSession(service, beam, gun)
+	ApplyHazard(session, &game.HazardEffect{Name: "EMP Storm", DisabledDamageTypes: []string{"ENERGY"}})
+
+	if _, err := service.PlayCommand(session, PlayerCommand{WeaponID: &beam.ID}); err == nil {
+		t.Error("Energy weapons should be offline in an EMP storm")
+	}
+	if _, err := service.PlayCommand(session, PlayerCommand{DamageType: Energy}); err == nil {
+		t.Error("Unarmed Energy attacks should be rejected too")
+	}
+	if session.TurnCount != 0 {
+		t.Errorf("Rejected commands must not play a turn, TurnCount %d", session.TurnCount)
+	}
+
+	// The auto-pilot falls back to what still works
+	cmd := service.AutoCommand(session)
+	if cmd.WeaponID == nil || *cmd.WeaponID != gun.ID {
+		t.Errorf("Expected the auto-pilot to pick the Kinetic gun, got %+v", cmd)
+	}
+
+	// Shields would normally draw Energy from the enemy AI
+	session.PlayerStats.Shields = 50
+	if got := service.ChooseEnemyAction(session); got != Kinetic {
+		t.Errorf("Expected Kinetic while Energy is disabled, got %s", got)
+	}
+}
+
+func TestHazardFallbackWhenKineticIsDisabled(t *testing.T) {
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 1000, MaxHP: 1000, BaseAttack: 20, Accuracy: 100, Speed: 50, IsPlayer: true}
+	enemy := UnitStats{HP: 1000, MaxHP: 1000, BaseAttack: 5, Accuracy: 100, Speed: 10}
+	session := service.NewSession(player, enemy, 5)
+	ApplyHazard(session, &game.HazardEffect{Name: "Magnetic Shear", DisabledDamageTypes: []string{"KINETIC", "ENERGY"}})
+
+	if got := session.usableType(Kinetic); got != Explosive {
+		t.Errorf("Expected the first enabled type, got %s", got)
+	}
+
+	// A driver stuck on a disabled type still gets through the battle
+	summary := service.RunBattle(session, func(*CombatSession) DamageType { return Kinetic }, 5)
+	if summary.Turns != 5 {
+		t.Errorf("Expected the battle to run its 5 turns, got %d", summary.Turns)
+	}
+	for _, ev := range summary.Events {
+		if ev.Actor == SidePlayer && session.DamageTypeDisabled(ev.DamageType) {
+			t.Errorf("Player attacked with disabled %s", ev.DamageType)
+		}
+	}
+
+	// Nothing left to fire: the lead loses its turns instead of stalling the loop
+	session = service.NewSession(player, enemy, 5)
+	ApplyHazard(session, &game.HazardEffect{Name: "Dead Zone", DisabledDamageTypes: []string{"KINETIC", "ENERGY", "EXPLOSIVE", "VOID"}})
+	if summary := service.RunCommands(session, service.AutoCommand, 8); summary.Turns != 8 {
+		t.Errorf("Expected the battle to time out at 8 turns, got %d", summary.Turns)
+	}
+}
+
+func TestHazardDamageMultiplier(t *testing.T) {
+	session := &CombatSession{Hazard: &game.HazardEffect{DamageMultipliers: map[string]float64{"VOID": 1.25}}}
+	if got := session.hazardDamage(40, Void); got != 50 {
+		t.Errorf("Expected 40 Void damage to become 50, got %d", got)
+	}
+	if got := session.hazardDamage(40, Kinetic); got != 40 {
+		t.Errorf("Types without a multiplier are untouched, got %d", got)
+	}
+	if got := (&CombatSession{}).hazardDamage(40, Void); got != 40 {
+		t.Errorf("A clear fight is untouched, got %d", got)
+	}
+}
@@ -0,0 +1,117 @@
+package combat
+
+import (
+	"math"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// HitBonus is what one attribute point or one unlocked matrix node adds to a pilot's unit
+type HitBonus struct {
+	CritChance float64 `yaml:"crit_chance"` // Percentage points when attacking
+	CritResist float64 `yaml:"crit_resist"` // Percentage points off the attacker's crit chance
+	CritDamage float64 `yaml:"crit_damage"` // Added to the critical damage multiplier
+	Evasion    float64 `yaml:"evasion"`
+}
+
+// HitOdds are the probabilities of one attacker hitting one defender, in percent
+type HitOdds struct {
+	HitChance      int     `json:"hit_chance"`
+	CritChance     int     `json:"crit_chance"` // Chance that a hit is critical
+	CritMultiplier float64 `json:"crit_multiplier"`
+}
+
+// TargetOdds pairs odds with the enemy they were computed against
+type TargetOdds struct {
+	Target int     `json:"target"`
+	Odds   HitOdds `json:"odds"`
+}
+
+func hitModel(cfg *BalanceConfig) (baseCrit, accuracyPerCrit int, critMultiplier float64, accuracyPerEvasion, maxMiss int) {
+	m := cfg.HitModel
+	baseCrit, accuracyPerCrit, critMultiplier = 5, 100, 1.5
+	accuracyPerEvasion, maxMiss = 10, 95
+	if m.BaseCritChance > 0 {
+		baseCrit = m.BaseCritChance
+	}
+	if m.AccuracyPerCrit > 0 {
+		accuracyPerCrit = m.AccuracyPerCrit
+	}
+	if m.CritMultiplier > 0 {
+		critMultiplier = m.CritMultiplier
+	}
+	if m.AccuracyPerEvasion > 0 {
+		accuracyPerEvasion = m.AccuracyPerEvasion
+	}
+	if m.MaxMissChance > 0 {
+		maxMiss = m.MaxMissChance
+	}
+	return
+}
+
+// ComputeOdds returns the hit and crit probabilities CalculateDamage will roll against
+func ComputeOdds(attacker UnitStats, defender UnitStats) HitOdds {
+	return computeOdds(Balance(), attacker, defender)
+}
+
+func computeOdds(cfg *BalanceConfig, attacker UnitStats, defender UnitStats) HitOdds {
+	baseCrit, accuracyPerCrit, critMultiplier, accuracyPerEvasion, maxMiss := hitModel(cfg)
+
+	miss := defender.Evasion - attacker.Accuracy/accuracyPerEvasion
+	miss = clampPercent(miss, maxMiss)
+
+	crit := baseCrit + attacker.Accuracy/accuracyPerCrit + attacker.CritChance - defender.CritResist
+	crit = clampPercent(crit, 100)
+
+	return HitOdds{
+		HitChance:      100 - miss,
+		CritChance:     crit,
+		CritMultiplier: critMultiplier + attacker.CritDamage,
+	}
+}
+
+func clampPercent(v, max int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// PlayerOdds previews the player lead's odds against every standing enemy
+func (c *CombatSession) PlayerOdds() []TargetOdds {
+	attacker := EffectiveStats(c.PlayerStats)
+	var odds []TargetOdds
+	for _, ref := range c.Standing(SideEnemy) {
+		odds = append(odds, TargetOdds{Target: ref.Index, Odds: ComputeOdds(attacker, EffectiveStats(*c.Unit(ref)))})
+	}
+	return odds
+}
+
+// applyPilotHitBonuses adds the crit and evasion bonuses of the pilot's attributes and unlocked matrix nodes
+func applyPilotHitBonuses(stats *UnitStats, pilot *game.PilotStats) {
+	var total HitBonus
+	add := func(b HitBonus, times float64) {
+		total.CritChance += b.CritChance * times
+		total.CritResist += b.CritResist * times
+		total.CritDamage += b.CritDamage * times
+		total.Evasion += b.Evasion * times
+	}
+
+	m := Balance().HitModel
+	for name, bonus := range m.Attributes {
+		add(bonus, float64(pilot.Attribute(name)))
+	}
+	for node, bonus := range m.Research {
+		if pilot.HasUnlocked(node) {
+			add(bonus, 1)
+		}
+	}
+
+	stats.CritChance += int(math.Round(total.CritChance))
+	stats.CritResist += int(math.Round(total.CritResist))
+	stats.CritDamage += total.CritDamage
+	stats.Evasion += int(math.Round(total.Evasion))
+}
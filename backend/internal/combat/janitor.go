@@ -0,0 +1,28 @@
+package combat
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunLogJanitor deletes expired, non-permanent battle records every interval until ctx is cancelled
+func RunLogJanitor(ctx context.Context, repo Repository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := repo.DeleteExpiredBattleRecords(ctx, time.Now())
+		if err != nil {
+			log.Printf("Combat log janitor: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Combat log janitor: removed %d expired battle records", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
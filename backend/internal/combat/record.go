@@ -0,0 +1,89 @@
+package combat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BattleLogRetention is how long a non-permanent battle record is kept before the janitor removes it
+const BattleLogRetention = 7 * 24 * time.Hour
+
+// BattleRecord is a finished battle stored in combat_logs
+type BattleRecord struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	MissionID   *uuid.UUID `json:"mission_id,omitempty"`
+	BattleData  BattleData `json:"battle_data"`
+	ImageURL    *string    `json:"image_url,omitempty"`
+	IsPermanent bool       `json:"is_permanent"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	SavedAt     *time.Time `json:"saved_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// BattleData is the JSONB payload of a battle record: enough to replay the fight from its seed
+type BattleData struct {
+	SessionID    uuid.UUID           `json:"session_id"`
+	EncounterID  *uuid.UUID          `json:"encounter_id,omitempty"`
+	Seed         int64               `json:"seed"`
+	Outcome      BattleOutcome       `json:"outcome"`
+	Turns        int                 `json:"turns"`
+	Participants []BattleParticipant `json:"participants"`
+	Events       []TurnEvent         `json:"events"`
+	Log          []string            `json:"log"`
+}
+
+type BattleParticipant struct {
+	Side      Side       `json:"side"`
+	Index     int        `json:"index"` // 0 = lead, 1..n = squad
+	VehicleID *uuid.UUID `json:"vehicle_id,omitempty"`
+	EnemyID   *uuid.UUID `json:"enemy_id,omitempty"`
+	Start     UnitStats  `json:"start"`
+	End       UnitStats  `json:"end"`
+}
+
+// NewBattleRecord builds the combat_logs entry for a finished session
+func (s *Service) NewBattleRecord(session *CombatSession) *BattleRecord {
+	now := time.Now()
+	expiresAt := now.Add(BattleLogRetention)
+
+	participants := []BattleParticipant{
+		{Side: SidePlayer, VehicleID: session.VehicleID, Start: session.StartPlayer, End: session.PlayerStats},
+		{Side: SideEnemy, EnemyID: session.EnemyID, Start: session.StartEnemy, End: session.EnemyStats},
+	}
+	for i, end := range session.PlayerSquad {
+		p := BattleParticipant{Side: SidePlayer, Index: i + 1, End: end}
+		if i < len(session.SquadVehicleIDs) {
+			p.VehicleID = &session.SquadVehicleIDs[i]
+		}
+		if i < len(session.StartPlayerSquad) {
+			p.Start = session.StartPlayerSquad[i]
+		}
+		participants = append(participants, p)
+	}
+	for i, end := range session.EnemySquad {
+		p := BattleParticipant{Side: SideEnemy, Index: i + 1, EnemyID: session.EnemyID, End: end}
+		if i < len(session.StartEnemySquad) {
+			p.Start = session.StartEnemySquad[i]
+		}
+		participants = append(participants, p)
+	}
+
+	return &BattleRecord{
+		ID:     uuid.New(),
+		UserID: session.UserID,
+		BattleData: BattleData{
+			SessionID:   session.ID,
+			EncounterID: session.EncounterID,
+			Seed:        session.Seed,
+			Outcome:     session.Outcome,
+			Turns:       session.TurnCount,
+			Participants: participants,
+			Events: session.Events,
+			Log:    session.Log,
+		},
+		ExpiresAt: &expiresAt,
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,32 @@
+package combat
+
+import "testing"
+
+func TestNewBattleRecordCapturesParticipants(t *testing.T) {
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 200, MaxHP: 200, BaseAttack: 80, Accuracy: 100, Speed: 50, IsPlayer: true}
+	enemy := UnitStats{HP: 60, MaxHP: 60, BaseAttack: 10, Accuracy: 100, Speed: 10}
+	session := service.NewSession(player, enemy, 7)
+
+	service.RunBattle(session, func(*CombatSession) DamageType { return Kinetic }, DefaultMaxTurns)
+	record := service.NewBattleRecord(session)
+
+	if record.BattleData.Seed != 7 || record.BattleData.Outcome != session.Outcome {
+		t.Errorf("Record should carry seed and outcome, got %d / %s", record.BattleData.Seed, record.BattleData.Outcome)
+	}
+	if len(record.BattleData.Participants) != 2 {
+		t.Fatalf("Expected 2 participants, got %d", len(record.BattleData.Participants))
+	}
+	if record.BattleData.Participants[1].Start.HP != 60 {
+		t.Errorf("Enemy start HP should be 60, got %d", record.BattleData.Participants[1].Start.HP)
+	}
+	if len(record.BattleData.Events) != len(session.Events) {
+		t.Errorf("Expected %d events, got %d", len(session.Events), len(record.BattleData.Events))
+	}
+	if record.IsPermanent || record.ExpiresAt == nil {
+		t.Fatal("New records should be temporary with an expiry")
+	}
+	if d := record.ExpiresAt.Sub(record.CreatedAt); d != BattleLogRetention {
+		t.Errorf("Expected expiry after %v, got %v", BattleLogRetention, d)
+	}
+}
@@ -0,0 +1,172 @@
+package combat
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionConflict means the session was saved by another request since it was loaded
+var ErrSessionConflict = errors.New("combat session was modified concurrently")
+
+type Repository interface {
+	// Combat Sessions (multi-turn battles)
+	CreateSession(ctx context.Context, session *CombatSession) error
+	GetSessionByID(ctx context.Context, id uuid.UUID) (*CombatSession, error)
+	GetSessionByEncounterID(ctx context.Context, encounterID uuid.UUID) (*CombatSession, error)
+	UpdateSession(ctx context.Context, session *CombatSession) error // Optimistic: ErrSessionConflict if Version is stale
+
+	// Battle Records (combat_logs)
+	CreateBattleRecord(ctx context.Context, record *BattleRecord) error
+	GetBattleRecordByID(ctx context.Context, id uuid.UUID) (*BattleRecord, error)
+	GetBattleRecordsByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]BattleRecord, error)
+	MarkBattleRecordPermanent(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredBattleRecords(ctx context.Context, now time.Time) (int64, error)
+}
+
+type combatRepository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &combatRepository{db: db}
+}
+
+func (r *combatRepository) CreateSession(ctx context.Context, s *CombatSession) error {
+	stateJSON, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO combat_sessions (id, user_id, encounter_id, enemy_id, seed, state, outcome) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err = r.db.ExecContext(ctx, query, s.ID, s.UserID, s.EncounterID, s.EnemyID, s.Seed, stateJSON, s.Outcome)
+	return err
+}
+
+func (r *combatRepository) GetSessionByID(ctx context.Context, id uuid.UUID) (*CombatSession, error) {
+	query := `SELECT state, version, created_at, updated_at FROM combat_sessions WHERE id = $1`
+	return r.scanSession(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *combatRepository) GetSessionByEncounterID(ctx context.Context, encounterID uuid.UUID) (*CombatSession, error) {
+	query := `SELECT state, version, created_at, updated_at FROM combat_sessions WHERE encounter_id = $1`
+	return r.scanSession(r.db.QueryRowContext(ctx, query, encounterID))
+}
+
+func (r *combatRepository) UpdateSession(ctx context.Context, s *CombatSession) error {
+	loaded := s.Version
+	s.Version++
+	stateJSON, err := json.Marshal(s)
+	if err != nil {
+		s.Version = loaded
+		return err
+	}
+	query := `UPDATE combat_sessions SET state = $1, outcome = $2, rewards_granted = $3, version = $4 WHERE id = $5 AND version = $6`
+	res, err := r.db.ExecContext(ctx, query, stateJSON, s.Outcome, s.RewardsGranted, s.Version, s.ID, loaded)
+	if err != nil {
+		s.Version = loaded
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		s.Version = loaded
+		return err
+	}
+	if n == 0 {
+		s.Version = loaded
+		return ErrSessionConflict
+	}
+	return nil
+}
+
+func (r *combatRepository) scanSession(row *sql.Row) (*CombatSession, error) {
+	var s CombatSession
+	var stateJSON []byte
+	var version int
+	err := row.Scan(&stateJSON, &version, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, updatedAt := s.CreatedAt, s.UpdatedAt
+	if err := json.Unmarshal(stateJSON, &s); err != nil {
+		return nil, err
+	}
+	s.CreatedAt, s.UpdatedAt = createdAt, updatedAt
+	s.Version = version // The column is authoritative for optimistic locking
+	return &s, nil
+}
+
+func (r *combatRepository) CreateBattleRecord(ctx context.Context, rec *BattleRecord) error {
+	dataJSON, err := json.Marshal(rec.BattleData)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO combat_logs (id, user_id, mission_id, battle_data, image_url, is_permanent, expires_at, saved_at, created_at) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err = r.db.ExecContext(ctx, query, rec.ID, rec.UserID, rec.MissionID, dataJSON, rec.ImageURL, rec.IsPermanent, rec.ExpiresAt, rec.SavedAt, rec.CreatedAt)
+	return err
+}
+
+func (r *combatRepository) GetBattleRecordByID(ctx context.Context, id uuid.UUID) (*BattleRecord, error) {
+	query := `SELECT id, user_id, mission_id, battle_data, image_url, is_permanent, expires_at, saved_at, created_at FROM combat_logs WHERE id = $1`
+	var rec BattleRecord
+	var dataJSON []byte
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&rec.ID, &rec.UserID, &rec.MissionID, &dataJSON, &rec.ImageURL, &rec.IsPermanent, &rec.ExpiresAt, &rec.SavedAt, &rec.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dataJSON, &rec.BattleData); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *combatRepository) GetBattleRecordsByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]BattleRecord, error) {
+	query := `SELECT id, user_id, mission_id, battle_data, image_url, is_permanent, expires_at, saved_at, created_at 
+	          FROM combat_logs WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []BattleRecord{}
+	for rows.Next() {
+		var rec BattleRecord
+		var dataJSON []byte
+		if err := rows.Scan(&rec.ID, &rec.UserID, &rec.MissionID, &dataJSON, &rec.ImageURL, &rec.IsPermanent, &rec.ExpiresAt, &rec.SavedAt, &rec.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(dataJSON, &rec.BattleData); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func (r *combatRepository) MarkBattleRecordPermanent(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE combat_logs SET is_permanent = TRUE, saved_at = CURRENT_TIMESTAMP, expires_at = NULL WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *combatRepository) DeleteExpiredBattleRecords(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM combat_logs WHERE is_permanent = FALSE AND expires_at IS NOT NULL AND expires_at < $1`
+	res, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
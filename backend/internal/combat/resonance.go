@@ -0,0 +1,72 @@
+package combat
+
+import (
+	"fmt"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+func resonanceDuration(balance *BalanceConfig) int {
+	if d := balance.Resonance.Duration; d > 0 {
+		return d
+	}
+	return 3
+}
+
+func resonanceCooldown() int {
+	if c := Balance().Resonance.Cooldown; c > 0 {
+		return c
+	}
+	return 3
+}
+
+// addResonance fills the player's gauge while Resonance Mode is off
+func addResonance(unit *UnitStats, amount float64) {
+	if !unit.IsPlayer || unit.IsResonanceActive || amount <= 0 {
+		return
+	}
+	unit.ResonanceGauge += amount
+	if unit.ResonanceGauge > 100 {
+		unit.ResonanceGauge = 100
+	}
+}
+
+// tickResonance runs at the end of every turn: active resonance counts down and builds Stress,
+// then the ability cools down before it can be used again
+func (s *Service) tickResonance(session *CombatSession) {
+	player := &session.PlayerStats
+	if !player.IsPlayer {
+		return
+	}
+
+	if player.IsResonanceActive {
+		session.StressGained += Balance().Resonance.StressPerTurn
+		player.ResonanceTurns--
+		if player.ResonanceTurns <= 0 {
+			player.IsResonanceActive = false
+			player.ResonanceTurns = 0
+			player.ResonanceCooldown = resonanceCooldown()
+			session.Log = append(session.Log, fmt.Sprintf("[SYSTEM] NEURAL RESONANCE DESYNCHRONIZED. RECALIBRATING FOR %d TURNS.", player.ResonanceCooldown))
+		}
+		return
+	}
+
+	if player.ResonanceCooldown > 0 {
+		player.ResonanceCooldown--
+	}
+}
+
+// ApplyPilotResults writes the battle's resonance gauge, accumulated Stress and remaining O2 back to the pilot
+func (s *Service) ApplyPilotResults(session *CombatSession, pilot *game.PilotStats) {
+	pilot.ResonanceGauge = session.PlayerStats.ResonanceGauge
+	if session.PlayerStats.MaxO2 > 0 {
+		pilot.CurrentO2 = session.PlayerStats.O2
+	}
+	pilot.Stress += session.StressGained
+	if pilot.Stress > 100 {
+		pilot.Stress = 100
+	}
+	if pilot.Metadata != nil {
+		delete(pilot.Metadata, "resonance_active") // Resonance never carries over into the next battle
+	}
+}
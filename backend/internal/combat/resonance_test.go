@@ -0,0 +1,78 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+func TestResonanceGainFromDamageTaken(t *testing.T) {
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.Resonance.GainRateTaken = 1
+		cfg.Resonance.GainRateDealt = 0
+	})
+
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 100, MaxHP: 100, IsPlayer: true, IsVehicle: true}
+	enemy := UnitStats{HP: 100, MaxHP: 100, BaseAttack: 30, Accuracy: 100, IsVehicle: true}
+	session := service.NewSession(player, enemy, 1)
+
+	result := service.resolveAttack(session, enemyLead, playerLead, Kinetic, nil)
+	if result.FinalDamage == 0 {
+		t.Fatal("expected the enemy to hit")
+	}
+	if session.PlayerStats.ResonanceGauge != float64(result.FinalDamage) {
+		t.Errorf("gauge = %v, want %d from damage taken", session.PlayerStats.ResonanceGauge, result.FinalDamage)
+	}
+}
+
+func TestResonanceDurationCooldownAndStress(t *testing.T) {
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.Resonance.Duration = 2
+		cfg.Resonance.Cooldown = 2
+		cfg.Resonance.StressPerTurn = 5
+	})
+
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 1000, MaxHP: 1000, BaseAttack: 1, IsPlayer: true, IsVehicle: true, ResonanceGauge: 100}
+	enemy := UnitStats{HP: 1000, MaxHP: 1000, BaseAttack: 1, IsVehicle: true}
+	session := service.NewSession(player, enemy, 1)
+
+	if !service.ActivateResonance(session) {
+		t.Fatal("full gauge should activate")
+	}
+
+	service.PlayTurn(session, Kinetic)
+	if !session.PlayerStats.IsResonanceActive {
+		t.Fatal("resonance should still be active after one of two turns")
+	}
+	service.PlayTurn(session, Kinetic)
+	if session.PlayerStats.IsResonanceActive || session.PlayerStats.ResonanceCooldown != 2 {
+		t.Fatalf("resonance should end into a 2-turn cooldown, got active=%v cooldown=%d",
+			session.PlayerStats.IsResonanceActive, session.PlayerStats.ResonanceCooldown)
+	}
+	if session.StressGained != 10 {
+		t.Errorf("stress gained = %d, want 10", session.StressGained)
+	}
+
+	// Cooling down: a full gauge is not enough
+	session.PlayerStats.ResonanceGauge = 100
+	if service.ActivateResonance(session) {
+		t.Fatal("resonance should not activate during cooldown")
+	}
+	service.PlayTurn(session, Kinetic)
+	service.PlayTurn(session, Kinetic)
+	if !service.ActivateResonance(session) {
+		t.Fatal("resonance should activate once the cooldown is over")
+	}
+
+	pilot := &game.PilotStats{Stress: 95, Metadata: map[string]interface{}{"resonance_active": true}}
+	session.PlayerStats.ResonanceGauge = 42
+	service.ApplyPilotResults(session, pilot)
+	if pilot.ResonanceGauge != 42 || pilot.Stress != 100 {
+		t.Errorf("pilot gauge %v stress %d, want 42 and 100 (capped)", pilot.ResonanceGauge, pilot.Stress)
+	}
+	if _, ok := pilot.Metadata["resonance_active"]; ok {
+		t.Error("resonance_active should be cleared")
+	}
+}
@@ -0,0 +1,175 @@
+package combat
+
+import (
+	"fmt"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// ScriptBuff is the status effect used by the script "buff" action; its modifier travels on the effect itself
+const ScriptBuff StatusEffectType = "SCRIPT_BUFF"
+
+const (
+	defaultScriptBuffDuration = 3
+	legacyHPLowThreshold      = 25 // player_hp_low fires under 25% HP
+)
+
+// ScriptState tracks one script event during a battle
+type ScriptState struct {
+	Fired    int  `json:"fired"`
+	Active   bool `json:"active"`              // Trigger condition held at the last check; repeat events re-arm once it clears
+	LastTurn int  `json:"last_turn,omitempty"` // Turn the event last fired on
+}
+
+// handleScriptedEvents checks every script trigger against the session and runs the actions that fire.
+// It is called after each hit, at turn start and on resonance activation; triggers fire on the edge of
+// their condition, so calling it more often never double-fires an event.
+func (s *Service) handleScriptedEvents(session *CombatSession) {
+	if !session.IsScripted {
+		return
+	}
+	if len(session.ScriptStates) < len(session.ScriptEvents) {
+		states := make([]ScriptState, len(session.ScriptEvents))
+		copy(states, session.ScriptStates)
+		session.ScriptStates = states
+	}
+
+	for i, event := range session.ScriptEvents {
+		state := &session.ScriptStates[i]
+
+		held := scriptConditionHolds(session, event, state)
+		rising := held && !state.Active
+		state.Active = held
+
+		if !rising || (state.Fired > 0 && !event.Repeat) {
+			continue
+		}
+
+		state.Fired++
+		state.LastTurn = session.TurnCount
+		s.runScriptAction(session, event)
+	}
+}
+
+func scriptConditionHolds(session *CombatSession, event game.ScriptEvent, state *ScriptState) bool {
+	player, enemy := session.PlayerStats, session.EnemyStats
+
+	switch event.Trigger {
+	case game.TriggerPlayerHPBelow:
+		return hpPercent(player) < event.Threshold
+	case game.TriggerPlayerHPLow:
+		threshold := event.Threshold
+		if threshold == 0 {
+			threshold = legacyHPLowThreshold
+		}
+		return hpPercent(player) < threshold
+	case game.TriggerEnemyHPBelow:
+		return hpPercent(enemy) < event.Threshold
+	case game.TriggerEnemyDefeated:
+		return enemy.HP <= 0
+	case game.TriggerBossPhase2:
+		return enemy.HP <= 0 && enemy.IsVehicle
+	case game.TriggerResonanceActivated:
+		return player.IsResonanceActive
+	case game.TriggerTurn:
+		turn := int(event.Threshold)
+		if turn < 1 || session.TurnCount < turn || state.LastTurn == session.TurnCount {
+			return false
+		}
+		if event.Repeat {
+			// Only true on the turns it fires so the edge detection re-arms in between
+			return session.TurnCount%turn == 0
+		}
+		return true
+	}
+	return false
+}
+
+func hpPercent(unit UnitStats) float64 {
+	if unit.MaxHP <= 0 {
+		return 0
+	}
+	return float64(unit.HP) * 100 / float64(unit.MaxHP)
+}
+
+func (s *Service) runScriptAction(session *CombatSession, event game.ScriptEvent) {
+	p := event.Params
+	target := &session.EnemyStats
+	if p.Target == game.ScriptTargetPlayer {
+		target = &session.PlayerStats
+	}
+
+	switch event.Action {
+	case game.ActionForceEject:
+		if !session.PlayerStats.IsVehicle {
+			return
+		}
+		s.ejectPilot(session, p.HP)
+
+	case game.ActionSpawnHumanPilot:
+		// Transform Boss to Human Pilot
+		hp, attack := Balance().BaseStats.BossPhase2HP, Balance().BaseStats.BossPhase2Attack
+		if p.HP > 0 {
+			hp = p.HP
+		}
+		if p.Attack > 0 {
+			attack = p.Attack
+		}
+		session.EnemyStats.IsVehicle = false
+		session.EnemyStats.HP = hp
+		session.EnemyStats.MaxHP = hp
+		session.EnemyStats.BaseAttack = attack
+		session.EnemyStats.Shields, session.EnemyStats.MaxShields = 0, 0
+		session.EnemyStats.IsResonanceActive = true
+		session.EnemyStats.ResonanceLevel = Balance().BaseStats.BossPhase2ResonanceLevel
+
+	case game.ActionSpawnAdds:
+		// Adds join the enemy squad, up to the squad size limit
+		count := p.Count
+		if count == 0 {
+			count = 1
+		}
+		add := session.EnemyStats
+		add.Effects = nil
+		add.IsResonanceActive, add.ResonanceLevel = false, 0
+		add.MaxHP = session.EnemyStats.MaxHP / 4
+		add.BaseAttack = session.EnemyStats.BaseAttack / 2
+		if p.HP > 0 {
+			add.MaxHP = p.HP
+		}
+		if p.Attack > 0 {
+			add.BaseAttack = p.Attack
+		}
+		if p.Defense > 0 {
+			add.TargetDefense = p.Defense
+		}
+		add.HP = add.MaxHP
+		add.Shields = add.MaxShields
+		spawned := 0
+		for ; spawned < count && session.UnitCount(SideEnemy) < MaxSquadSize(); spawned++ {
+			s.AddUnit(session, SideEnemy, add)
+		}
+		session.Log = append(session.Log, fmt.Sprintf("[SCRIPT] %d hostile reinforcement(s) deployed", spawned))
+
+	case game.ActionHeal:
+		amount := p.Amount + int(float64(target.MaxHP)*p.Percent/100)
+		target.HP += amount
+		if target.HP > target.MaxHP {
+			target.HP = target.MaxHP
+		}
+
+	case game.ActionChangeDamageType:
+		session.EnemyDamageType = DamageType(p.DamageType)
+
+	case game.ActionBuff:
+		duration := p.Duration
+		if duration == 0 {
+			duration = defaultScriptBuffDuration
+		}
+		ApplyStatusEffect(Balance(), target, StatusEffect{Type: ScriptBuff, Duration: duration, Stacks: 1, Stat: p.Stat, Modifier: p.Modifier})
+	}
+
+	if event.Dialogue != "" {
+		session.Log = append(session.Log, "[SCRIPT] "+event.Dialogue)
+	}
+}
@@ -0,0 +1,149 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+func scriptedSession(events ...game.ScriptEvent) *CombatSession {
+	service := NewService(NewEngine())
+	player := UnitStats{HP: 100, MaxHP: 100, BaseAttack: 10, Accuracy: 100, Speed: 50, IsPlayer: true, IsVehicle: true}
+	enemy := UnitStats{HP: 100, MaxHP: 100, BaseAttack: 10, Accuracy: 100, Speed: 10, IsVehicle: true}
+	session := service.NewSession(player, enemy, 1)
+	session.IsScripted = true
+	session.ScriptEvents = events
+	return session
+}
+
+func TestScriptHPThresholdFiresOnce(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(game.ScriptEvent{
+		Trigger: game.TriggerEnemyHPBelow, Threshold: 50, Action: game.ActionHeal,
+		Params: game.ScriptParams{Amount: 10}, Dialogue: "Repairs engaged",
+	})
+
+	session.EnemyStats.HP = 40
+	service.handleScriptedEvents(session)
+	if session.EnemyStats.HP != 50 {
+		t.Fatalf("Expected heal to 50, got %d", session.EnemyStats.HP)
+	}
+
+	session.EnemyStats.HP = 30
+	service.handleScriptedEvents(session)
+	if session.EnemyStats.HP != 30 {
+		t.Errorf("One-shot event should not fire again, HP %d", session.EnemyStats.HP)
+	}
+}
+
+func TestScriptRepeatRearmsAfterConditionClears(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(game.ScriptEvent{
+		Trigger: game.TriggerEnemyHPBelow, Threshold: 50, Action: game.ActionHeal,
+		Params: game.ScriptParams{Percent: 30}, Repeat: true,
+	})
+
+	session.EnemyStats.HP = 40
+	service.handleScriptedEvents(session)
+	service.handleScriptedEvents(session) // Condition cleared (70 HP), nothing fires
+	session.EnemyStats.HP = 20
+	service.handleScriptedEvents(session)
+
+	if session.ScriptStates[0].Fired != 2 || session.EnemyStats.HP != 50 {
+		t.Errorf("Expected 2 fires and 50 HP, got %d fires and %d HP", session.ScriptStates[0].Fired, session.EnemyStats.HP)
+	}
+}
+
+func TestScriptTurnTriggerRepeats(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(game.ScriptEvent{
+		Trigger: game.TriggerTurn, Threshold: 2, Action: game.ActionDialogue, Dialogue: "Tick", Repeat: true,
+	})
+
+	for turn := 1; turn <= 6; turn++ {
+		session.TurnCount = turn
+		service.handleScriptedEvents(session)
+		service.handleScriptedEvents(session)
+	}
+
+	if session.ScriptStates[0].Fired != 3 {
+		t.Errorf("Expected to fire on turns 2, 4 and 6, fired %d times", session.ScriptStates[0].Fired)
+	}
+}
+
+func TestScriptLegacyBossPhase2(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(game.ScriptEvent{
+		Trigger: game.TriggerBossPhase2, Action: game.ActionSpawnHumanPilot, Params: game.ScriptParams{HP: 80},
+	})
+
+	session.EnemyStats.HP = 0
+	service.handleScriptedEvents(session)
+
+	if session.EnemyStats.IsVehicle || session.EnemyStats.HP != 80 {
+		t.Errorf("Boss should continue on foot with 80 HP, got vehicle=%v HP=%d", session.EnemyStats.IsVehicle, session.EnemyStats.HP)
+	}
+	if service.checkOutcome(session) != OutcomeOngoing {
+		t.Error("Phase 2 should keep the battle going")
+	}
+}
+
+func TestScriptSpawnAddsJoinEnemySquad(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(game.ScriptEvent{
+		Trigger: game.TriggerTurn, Threshold: 1, Action: game.ActionSpawnAdds,
+		Params: game.ScriptParams{Count: 2, HP: 30},
+	})
+	session.TurnCount = 1
+	service.handleScriptedEvents(session)
+
+	if len(session.EnemySquad) != 2 || session.EnemySquad[0].HP != 30 {
+		t.Fatalf("Expected 2 adds with 30 HP, got %+v", session.EnemySquad)
+	}
+
+	session.EnemyStats.HP = 0
+	if service.checkOutcome(session) != OutcomeOngoing {
+		t.Fatal("Battle should continue while adds are standing")
+	}
+	session.EnemySquad[0].HP, session.EnemySquad[1].HP = 0, 0
+	if service.checkOutcome(session) != OutcomeVictory {
+		t.Error("Battle should be won once all adds are down")
+	}
+}
+
+func TestScriptBuffAndDamageType(t *testing.T) {
+	service := NewService(NewEngine())
+	session := scriptedSession(
+		game.ScriptEvent{Trigger: game.TriggerTurn, Threshold: 1, Action: game.ActionBuff, Params: game.ScriptParams{Stat: "attack", Modifier: 0.5}},
+		game.ScriptEvent{Trigger: game.TriggerTurn, Threshold: 1, Action: game.ActionChangeDamageType, Params: game.ScriptParams{DamageType: "VOID"}},
+	)
+	session.TurnCount = 1
+	service.handleScriptedEvents(session)
+
+	if got := EffectiveStats(session.EnemyStats).BaseAttack; got != 15 {
+		t.Errorf("Expected buffed attack 15, got %d", got)
+	}
+	if service.ChooseEnemyAction(session) != Void {
+		t.Error("Enemy should switch to VOID")
+	}
+}
+
+func TestScriptEventValidate(t *testing.T) {
+	valid := game.ScriptEvent{Trigger: game.TriggerPlayerHPLow, Action: game.ActionForceEject}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Legacy event should validate: %v", err)
+	}
+
+	invalid := []game.ScriptEvent{
+		{Trigger: "on_whim", Action: game.ActionHeal},
+		{Trigger: game.TriggerTurn, Action: "explode"},
+		{Trigger: game.TriggerEnemyHPBelow, Action: game.ActionDialogue, Dialogue: "x"},
+		{Trigger: game.TriggerTurn, Threshold: 2, Action: game.ActionChangeDamageType, Params: game.ScriptParams{DamageType: "PLASMA"}},
+		{Trigger: game.TriggerTurn, Threshold: 2, Action: game.ActionBuff, Params: game.ScriptParams{Stat: "luck", Modifier: 1}},
+	}
+	for _, event := range invalid {
+		if err := event.Validate(); err == nil {
+			t.Errorf("Expected %s/%s to be rejected", event.Trigger, event.Action)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package combat
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/ryudokung/Project-0/backend/internal/vehicle"
 	"github.com/ryudokung/Project-0/backend/internal/game"
 )
@@ -13,12 +17,36 @@ func NewService(engine *Engine) *Service {
 	return &Service{engine: engine}
 }
 
-// MapVehicleToUnitStats converts a Vehicle and its equipped items into UnitStats for the combat engine
+// MapVehicleToUnitStats converts a Vehicle and its equipped items into UnitStats for the combat engine.
+// Without a vehicle the pilot fights on foot with default suit-less stats (see MapPilotToUnitStats).
 func (s *Service) MapVehicleToUnitStats(v *vehicle.Vehicle, items []vehicle.Item, pilot *game.PilotStats) UnitStats {
-	// Default stats for Pilot Only mode
+	if v == nil {
+		return s.MapPilotToUnitStats(nil, nil, pilot)
+	}
+
+	stats := UnitStats{
+		IsVehicle:         true,
+		HP:                v.Stats.HP,
+		MaxHP:             v.Stats.HP,
+		BaseAttack:        v.Stats.Attack,
+		TargetDefense:     v.Stats.Defense,
+		DefenseEfficiency: Balance().BaseStats.DefaultDefenseEfficiency,
+		Accuracy:          Balance().BaseStats.DefaultAccuracy,
+		Evasion:           v.Stats.Speed / 10,
+		Speed:             v.Stats.Speed,
+		ArmorType:         ArmorForClass(string(v.Class)),
+	}
+	applyEquippedItems(&stats, items)
+	applyPilotStats(&stats, pilot)
+	return stats
+}
+
+// MapPilotToUnitStats builds on-foot (EVA) stats from the pilot's exosuit and the parts equipped on it.
+// A nil suit leaves the bare pilot stats from the balance config.
+func (s *Service) MapPilotToUnitStats(suit *vehicle.Item, parts []vehicle.Item, pilot *game.PilotStats) UnitStats {
+	eva := Balance().EVA
 	stats := UnitStats{
 		HP:                100,
-		MaxHP:             100,
 		BaseAttack:        10,
 		TargetDefense:     5,
 		DefenseEfficiency: 0.3,
@@ -26,136 +54,308 @@ func (s *Service) MapVehicleToUnitStats(v *vehicle.Vehicle, items []vehicle.Item
 		Evasion:           10,
 		Speed:             50,
 	}
+	if eva.BaseHP > 0 {
+		stats.HP = eva.BaseHP
+	}
+	if eva.BaseAttack > 0 {
+		stats.BaseAttack = eva.BaseAttack
+	}
+	if eva.BaseDefense > 0 {
+		stats.TargetDefense = eva.BaseDefense
+	}
+	if eva.BaseSpeed > 0 {
+		stats.Speed = eva.BaseSpeed
+	}
 
-	if v != nil {
-		stats.IsVehicle = true
-		stats.HP = v.Stats.HP
-		stats.MaxHP = v.Stats.HP
-		stats.BaseAttack = v.Stats.Attack
-		stats.TargetDefense = v.Stats.Defense
-		stats.DefenseEfficiency = GlobalBalance.BaseStats.DefaultDefenseEfficiency
-		stats.Accuracy = GlobalBalance.BaseStats.DefaultAccuracy
-		stats.Evasion = v.Stats.Speed / 10
-		stats.Speed = v.Stats.Speed
-
-		// Apply Item Bonuses
-		for _, i := range items {
-			// Only count equipped items (though the query should filter this)
-			if i.IsEquipped {
-				stats.HP += i.Stats.BonusHP
-				stats.MaxHP += i.Stats.BonusHP
-				stats.BaseAttack += i.Stats.BonusAttack
-				stats.TargetDefense += i.Stats.BonusDefense
+	// A BROKEN suit is dead weight: its own stats and its parts give nothing
+	if suit != nil && suit.Condition != vehicle.ConditionBroken {
+		stats.HP += suit.Stats.HP + suit.Stats.BonusHP
+		stats.BaseAttack += suit.Stats.Attack + suit.Stats.BonusAttack
+		stats.TargetDefense += suit.Stats.Defense + suit.Stats.BonusDefense
+		stats.Speed += suit.Stats.Speed
+		stats.Evasion += suit.Stats.Speed / 10
+		applyEquippedItems(&stats, parts)
+	}
+	stats.MaxHP = stats.HP
+
+	applyPilotStats(&stats, pilot)
+	return stats
+}
+
+// applyEquippedItems adds the bonuses, weapons and shield generators of equipped, working parts
+func applyEquippedItems(stats *UnitStats, items []vehicle.Item) {
+	shieldGeneration := 0
+	for _, i := range items {
+		// Only count equipped items (though the query should filter this); BROKEN parts give nothing
+		if i.IsEquipped && i.Condition != vehicle.ConditionBroken {
+			stats.HP += i.Stats.BonusHP
+			stats.MaxHP += i.Stats.BonusHP
+			stats.BaseAttack += i.Stats.BonusAttack
+			stats.TargetDefense += i.Stats.BonusDefense
+			shieldGeneration += i.Stats.ShieldGeneration
+			if weapon, ok := WeaponFromItem(i); ok {
+				stats.Weapons = append(stats.Weapons, weapon)
 			}
 		}
-	} else {
-		stats.IsVehicle = false
+	}
+
+	// Weapons draw from a shared energy pool
+	if len(stats.Weapons) > 0 {
+		stats.MaxEnergy, stats.EnergyRegen = weaponEnergy()
+		stats.Energy = stats.MaxEnergy
+	}
+
+	// Shield pool from equipped generators (starts full, regenerates each turn)
+	stats.MaxShields = shieldGeneration * Balance().Shields.CapacityPerGeneration
+	stats.Shields = stats.MaxShields
+	stats.ShieldRegen = shieldGeneration
+}
+
+// applyPilotStats layers the pilot's sync rate, resonance and attributes on top of the unit
+func applyPilotStats(stats *UnitStats, pilot *game.PilotStats) {
+	if pilot == nil {
+		return
 	}
 
 	// Apply Neural Resonance Bonus (Newtype effect)
-	if pilot != nil {
-		stats.IsPlayer = true
-		stats.ResonanceLevel = pilot.ResonanceLevel
-		stats.ResonanceGauge = pilot.ResonanceGauge
-		if active, ok := pilot.Metadata["resonance_active"].(bool); ok && active {
-			stats.IsResonanceActive = true
+	stats.IsPlayer = true
+	stats.ResonanceLevel = pilot.ResonanceLevel
+	stats.ResonanceGauge = pilot.ResonanceGauge
+	if active, ok := pilot.Metadata["resonance_active"].(bool); ok && active {
+		stats.IsResonanceActive = true
+		stats.ResonanceTurns = resonanceDuration(Balance())
+	}
+
+	// Life support: O2 only drains once the pilot is on foot
+	stats.O2 = pilot.CurrentO2
+	stats.MaxO2 = 100
+	if stats.O2 > stats.MaxO2 {
+		stats.MaxO2 = stats.O2
+	}
+
+	// Calculate Sync Rate Multiplier
+	syncRate := Balance().Progression.BaseSyncRate + (float64(pilot.SyncLevel-1) * Balance().Progression.SyncRatePerLevel)
+
+	// Apply Sync Rate to Combat Stats (ECP logic)
+	stats.BaseAttack = int(float64(stats.BaseAttack) * syncRate)
+	stats.TargetDefense = int(float64(stats.TargetDefense) * syncRate)
+
+	// Every level of resonance increases Accuracy and Evasion
+	stats.Accuracy += pilot.ResonanceLevel * Balance().Resonance.BonusAccuracyPerLevel
+	stats.Evasion += pilot.ResonanceLevel * Balance().Resonance.BonusEvasionPerLevel
+
+	// Character attributes and Engineering Matrix nodes (e.g. Shock Absorbers)
+	applyPilotHitBonuses(stats, pilot)
+}
+
+// MapEnemyBlueprintToUnitStats builds the combat stats for a blueprint-defined NPC enemy
+func (s *Service) MapEnemyBlueprintToUnitStats(bp game.EnemyBlueprint) UnitStats {
+	return UnitStats{
+		HP:                bp.Stats.HP,
+		MaxHP:             bp.Stats.HP,
+		BaseAttack:        bp.Stats.Attack,
+		TargetDefense:     bp.Stats.Defense,
+		DefenseEfficiency: Balance().BaseStats.DefaultDefenseEfficiency,
+		Accuracy:          Balance().BaseStats.DefaultAccuracy,
+		Evasion:           bp.Stats.Speed / 10,
+		Speed:             bp.Stats.Speed,
+		IsVehicle:         bp.Type != "HUMAN", // Infantry fights on foot
+		ArmorType:         enemyArmor(bp),
+	}
+}
+
+// enemyArmor uses the blueprint's own armor_type, falling back to its class
+func enemyArmor(bp game.EnemyBlueprint) DamageType {
+	if bp.ArmorType != "" {
+		return DamageType(bp.ArmorType)
+	}
+	return ArmorForClass(bp.Class)
+}
+
+type CombatSession struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	EncounterID   *uuid.UUID         `json:"encounter_id,omitempty"`
+	EnemyID       *uuid.UUID         `json:"enemy_id,omitempty"`
+	VehicleID     *uuid.UUID         `json:"vehicle_id,omitempty"`
+	PlayerStats   UnitStats          `json:"player_stats"`
+	EnemyStats    UnitStats          `json:"enemy_stats"`
+	PlayerSquad   []UnitStats        `json:"player_squad,omitempty"` // Wingmen fighting beside the player (indices 1..n)
+	EnemySquad    []UnitStats        `json:"enemy_squad,omitempty"`  // Enemies beside the lead enemy (indices 1..n)
+	SquadVehicleIDs []uuid.UUID      `json:"squad_vehicle_ids,omitempty"` // Vehicles behind PlayerSquad, in order
+	StressGained  int                `json:"stress_gained,omitempty"` // Pilot Stress from Resonance Mode, applied when the battle ends
+	ExosuitID     *uuid.UUID         `json:"exosuit_id,omitempty"`   // Suit the pilot wears on foot
+	EjectStats    *UnitStats         `json:"eject_stats,omitempty"`  // On-foot stats the player lead continues with after force_eject
+	Loot          *game.LootDrop     `json:"loot,omitempty"`         // Pre-rolled drop, granted only on victory
+	Hazard        *game.HazardEffect `json:"hazard,omitempty"`       // Environmental hazard over the whole fight
+	IsScripted    bool               `json:"is_scripted"`
+	ScriptEvents  []game.ScriptEvent `json:"script_events,omitempty"` // We'll need to import game or move the struct
+	ScriptStates  []ScriptState      `json:"script_states,omitempty"`  // Parallel to ScriptEvents
+	EnemyDamageType DamageType       `json:"enemy_damage_type,omitempty"` // Forced by change_damage_type; empty lets the AI choose
+	TurnCount     int                `json:"turn_count"`
+	Log           []string           `json:"log"`
+	Seed          int64              `json:"seed"`
+	Draws         int64              `json:"draws"` // RNG values consumed so far, used to resume a persisted fight
+	Events        []TurnEvent        `json:"events"`
+	Outcome       BattleOutcome      `json:"outcome"`
+	StartPlayer   UnitStats          `json:"start_player"` // Stats at the start of the fight (replays, battle records)
+	StartEnemy    UnitStats          `json:"start_enemy"`
+	StartPlayerSquad []UnitStats     `json:"start_player_squad,omitempty"`
+	StartEnemySquad  []UnitStats     `json:"start_enemy_squad,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       int                `json:"version"` // Bumped on every save; a stale version is rejected
+	RewardsGranted bool              `json:"rewards_granted"`   // Every end-of-battle step below has been applied
+	GrantedSteps  []string           `json:"granted_steps,omitempty"` // End-of-battle steps claimed so far (see Handler.finalizeBattle)
+
+	engine *Engine // Per-session engine; nil falls back to the service engine
+}
+
+// Granted reports whether the end-of-battle step has already been claimed
+func (s *CombatSession) Granted(step string) bool {
+	for _, g := range s.GrantedSteps {
+		if g == step {
+			return true
 		}
+	}
+	return false
+}
 
-		// Calculate Sync Rate Multiplier
-		syncRate := GlobalBalance.Progression.BaseSyncRate + (float64(pilot.SyncLevel-1) * GlobalBalance.Progression.SyncRatePerLevel)
-		
-		// Apply Sync Rate to Combat Stats (ECP logic)
-		stats.BaseAttack = int(float64(stats.BaseAttack) * syncRate)
-		stats.TargetDefense = int(float64(stats.TargetDefense) * syncRate)
+// MarkGranted claims an end-of-battle step
+func (s *CombatSession) MarkGranted(step string) {
+	if !s.Granted(step) {
+		s.GrantedSteps = append(s.GrantedSteps, step)
+	}
+}
 
-		// Every level of resonance increases Accuracy and Evasion
-		stats.Accuracy += pilot.ResonanceLevel * GlobalBalance.Resonance.BonusAccuracyPerLevel
-		stats.Evasion += pilot.ResonanceLevel * GlobalBalance.Resonance.BonusEvasionPerLevel
+// UnmarkGranted releases the claim on a step that failed to apply, so a retry runs it again
+func (s *CombatSession) UnmarkGranted(step string) {
+	for i, g := range s.GrantedSteps {
+		if g == step {
+			s.GrantedSteps = append(s.GrantedSteps[:i], s.GrantedSteps[i+1:]...)
+			return
+		}
 	}
+}
 
-	return stats
+// NewSession creates a combat session with its own seeded engine so the whole fight can be replayed from Seed
+func (s *Service) NewSession(player, enemy UnitStats, seed int64) *CombatSession {
+	return &CombatSession{
+		PlayerStats: player,
+		EnemyStats:  enemy,
+		StartPlayer: player,
+		StartEnemy:  enemy,
+		Seed:        seed,
+		engine:      NewEngineWithSeed(seed),
+	}
 }
 
-type ScriptAction string
+func (s *Service) engineFor(session *CombatSession) *Engine {
+	if session.engine != nil {
+		return session.engine
+	}
+	return s.engine
+}
 
-const (
-	ActionForceEject      ScriptAction = "force_eject"
-	ActionSpawnHumanPilot ScriptAction = "spawn_human_pilot"
-)
+// Snapshot records the engine position on the session so it can be persisted
+func (s *Service) Snapshot(session *CombatSession) {
+	if session.engine != nil {
+		session.Draws = session.engine.Draws()
+	}
+}
 
-type CombatSession struct {
-	PlayerStats   UnitStats
-	EnemyStats    UnitStats
-	IsScripted    bool
-	ScriptEvents  []game.ScriptEvent // We'll need to import game or move the struct
-	TurnCount     int
-	Log           []string
+// Resume reattaches a seeded engine to a session loaded from storage, at the draw where it stopped
+func (s *Service) Resume(session *CombatSession) {
+	session.engine = RestoreEngine(session.Seed, session.Draws)
 }
 
-// ExecuteAttack runs a single attack cycle between two vehicles
-func (s *Service) ExecuteAttack(session *CombatSession, dmgType DamageType) CombatResult {
-	result := s.engine.CalculateDamage(session.PlayerStats, session.EnemyStats, dmgType)
+// ExecuteAttack runs a single attack from the player lead on the lead enemy. Armed units must fire
+// one of their weapons (validated like PlayCommand); only unarmed units choose cmd.DamageType.
+func (s *Service) ExecuteAttack(session *CombatSession, cmd PlayerCommand) (CombatResult, error) {
+	lead := &session.PlayerStats
+	if cmd.WeaponID == nil {
+		if len(lead.Weapons) > 0 {
+			return CombatResult{}, fmt.Errorf("armed units must attack with an equipped weapon")
+		}
+		dmgType := cmd.DamageType
+		if dmgType == "" {
+			dmgType = Kinetic
+		}
+		if session.DamageTypeDisabled(dmgType) {
+			return CombatResult{}, fmt.Errorf("%s attacks are disabled by the hazard", dmgType)
+		}
+		return s.resolveAttack(session, playerLead, enemyLead, dmgType, nil), nil
+	}
+
+	weapon := findWeapon(lead, *cmd.WeaponID)
+	if weapon == nil {
+		return CombatResult{}, fmt.Errorf("weapon is not equipped on the attacking vehicle")
+	}
+	if err := checkWeapon(lead, weapon, session.TurnCount+1); err != nil {
+		return CombatResult{}, err
+	}
+	fire(lead, weapon, session.TurnCount+1)
+	return s.resolveAttack(session, playerLead, enemyLead, weapon.DamageType, weapon), nil
+}
+
+// resolveAttack applies one hit from the acting unit to its target. A weapon (may be nil) adds its attack to the shot.
+func (s *Service) resolveAttack(session *CombatSession, actor, target UnitRef, dmgType DamageType, weapon *Weapon) CombatResult {
+	attacker, defender := session.Unit(actor), session.Unit(target)
+	// One balance snapshot for the whole hit, the same one CombatResult.BalanceVersion reports
+	balance := CurrentBalance()
+	cfg := &balance.Config
+
+	attackerStats := EffectiveStats(*attacker)
+	if weapon != nil {
+		attackerStats.BaseAttack += weapon.Attack
+	}
+	result := s.engineFor(session).calculateDamage(balance, attackerStats, EffectiveStats(*defender), dmgType)
+	result.FinalDamage = session.hazardDamage(result.FinalDamage, dmgType)
 	
+	// Shields soak the hit first, the rest goes to HP
+	hpDamage, shieldDamage := AbsorbDamage(cfg, defender, result.FinalDamage, dmgType)
+	result.ShieldDamage = shieldDamage
+
 	// Update HP
-	session.EnemyStats.HP -= result.FinalDamage
-	if session.EnemyStats.HP < 0 {
-		session.EnemyStats.HP = 0
-	}
-
-	// Build Resonance Gauge for Player
-	if session.PlayerStats.IsPlayer && !session.PlayerStats.IsResonanceActive {
-		// Gain gauge based on damage dealt
-		gain := float64(result.FinalDamage) * GlobalBalance.Resonance.GainRateDealt
-		session.PlayerStats.ResonanceGauge += gain
-		if session.PlayerStats.ResonanceGauge > 100 {
-			session.PlayerStats.ResonanceGauge = 100
-		}
+	defender.HP -= hpDamage
+	if defender.HP < 0 {
+		defender.HP = 0
 	}
 
-	// Check for Scripted Triggers
-	if session.IsScripted {
-		s.handleScriptedEvents(session)
+	// Status effects stick to the defender and tick on its turns
+	if result.AppliedEffect != nil {
+		ApplyStatusEffect(cfg, defender, *result.AppliedEffect)
 	}
 
+	// Explosive hits splash the rest of the target's squad
+	if dmgType == Explosive && !result.IsMiss && result.FinalDamage > 0 {
+		result.Splash = applySplash(cfg, session, target, result.FinalDamage)
+	}
+
+	// Build Resonance Gauge for Player from damage dealt and taken
+	if actor == playerLead {
+		addResonance(&session.PlayerStats, float64(result.FinalDamage)*cfg.Resonance.GainRateDealt)
+	}
+	if target == playerLead {
+		addResonance(&session.PlayerStats, float64(result.FinalDamage)*cfg.Resonance.GainRateTaken)
+	}
+
+	// Check for Scripted Triggers
+	s.handleScriptedEvents(session)
+
 	return result
 }
 
-// ActivateResonance triggers Resonance Mode if the gauge is full
+// ActivateResonance triggers Resonance Mode if the gauge is full and the ability is off cooldown
 func (s *Service) ActivateResonance(session *CombatSession) bool {
-	if session.PlayerStats.ResonanceGauge >= 100 {
-		session.PlayerStats.IsResonanceActive = true
-		session.PlayerStats.ResonanceGauge = 0
+	player := &session.PlayerStats
+	if player.ResonanceGauge >= 100 && !player.IsResonanceActive && player.ResonanceCooldown == 0 {
+		player.IsResonanceActive = true
+		player.ResonanceTurns = resonanceDuration(Balance())
+		player.ResonanceGauge = 0
 		session.Log = append(session.Log, "[SYSTEM] NEURAL RESONANCE SYNCHRONIZED. SCALE SUPPRESSION BYPASSED.")
+		s.handleScriptedEvents(session)
 		return true
 	}
 	return false
 }
-
-func (s *Service) handleScriptedEvents(session *CombatSession) {
-	// 1. Player HP Low Trigger
-	if session.PlayerStats.IsPlayer && session.PlayerStats.HP < (session.PlayerStats.MaxHP / 4) && session.PlayerStats.IsVehicle {
-		for _, event := range session.ScriptEvents {
-			if event.Trigger == "player_hp_low" && event.Action == string(ActionForceEject) {
-				session.PlayerStats.IsVehicle = false
-				session.PlayerStats.HP = GlobalBalance.BaseStats.ForcedSurvivalHP
-				session.Log = append(session.Log, "[SCRIPT] "+event.Dialogue)
-			}
-		}
-	}
-
-	// 2. Boss HP Zero Trigger (Phase 2)
-	if !session.EnemyStats.IsPlayer && session.EnemyStats.HP <= 0 && session.EnemyStats.IsVehicle {
-		for _, event := range session.ScriptEvents {
-			if event.Trigger == "boss_phase_2" && event.Action == string(ActionSpawnHumanPilot) {
-				// Transform Boss to Human Pilot
-				session.EnemyStats.IsVehicle = false
-				session.EnemyStats.HP = GlobalBalance.BaseStats.BossPhase2HP
-				session.EnemyStats.MaxHP = GlobalBalance.BaseStats.BossPhase2HP
-				session.EnemyStats.BaseAttack = GlobalBalance.BaseStats.BossPhase2Attack
-				session.EnemyStats.IsResonanceActive = true
-				session.EnemyStats.ResonanceLevel = GlobalBalance.BaseStats.BossPhase2ResonanceLevel
-				session.Log = append(session.Log, "[SCRIPT] "+event.Dialogue)
-			}
-		}
-	}
-}
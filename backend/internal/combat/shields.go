@@ -0,0 +1,49 @@
+package combat
+
+// AbsorbDamage runs a hit through the defender's shield pool and returns the damage left for HP.
+// Energy strips shields faster and Void bypasses them (ratios from the balance config).
+func AbsorbDamage(balance *BalanceConfig, defender *UnitStats, damage int, dmgType DamageType) (hpDamage int, shieldDamage int) {
+	if defender.Shields <= 0 || damage <= 0 {
+		return damage, 0
+	}
+
+	bypass := 0
+	if dmgType == Void {
+		bypass = int(float64(damage) * balance.Shields.VoidBypassRatio)
+		if bypass > damage {
+			bypass = damage
+		}
+	}
+	through := damage - bypass
+
+	strip := 1.0
+	if dmgType == Energy && balance.Shields.EnergyStripMultiplier > 0 {
+		strip = balance.Shields.EnergyStripMultiplier
+	}
+
+	// Shield points this hit could remove, capped by what is left in the pool
+	shieldDamage = int(float64(through) * strip)
+	if shieldDamage > defender.Shields {
+		shieldDamage = defender.Shields
+	}
+	defender.Shields -= shieldDamage
+
+	// Damage points spent on the shield never reach the hull
+	absorbed := int(float64(shieldDamage)/strip + 0.5)
+	if absorbed > through {
+		absorbed = through
+	}
+
+	return through - absorbed + bypass, shieldDamage
+}
+
+// RegenerateShields restores the unit's per-turn shield regeneration up to its max
+func RegenerateShields(unit *UnitStats) {
+	if unit.ShieldRegen <= 0 || unit.HP <= 0 {
+		return
+	}
+	unit.Shields += unit.ShieldRegen
+	if unit.Shields > unit.MaxShields {
+		unit.Shields = unit.MaxShields
+	}
+}
@@ -0,0 +1,41 @@
+package combat
+
+import (
+	"testing"
+)
+
+func TestAbsorbDamageByType(t *testing.T) {
+	withBalance(t, func(cfg *BalanceConfig) {
+		cfg.Shields.EnergyStripMultiplier = 1.5
+		cfg.Shields.VoidBypassRatio = 1.0
+	})
+
+	// Kinetic: 1 shield point per damage point
+	unit := UnitStats{Shields: 20}
+	hp, shield := AbsorbDamage(Balance(), &unit, 30, Kinetic)
+	if hp != 10 || shield != 20 || unit.Shields != 0 {
+		t.Errorf("Kinetic: expected 10 HP / 20 shield damage, got %d / %d (shields left %d)", hp, shield, unit.Shields)
+	}
+
+	// Energy: strips 1.5 shield points per damage point
+	unit = UnitStats{Shields: 30}
+	hp, shield = AbsorbDamage(Balance(), &unit, 30, Energy)
+	if hp != 10 || shield != 30 {
+		t.Errorf("Energy: expected 10 HP / 30 shield damage, got %d / %d", hp, shield)
+	}
+
+	// Void: bypasses shields entirely
+	unit = UnitStats{Shields: 50}
+	hp, shield = AbsorbDamage(Balance(), &unit, 30, Void)
+	if hp != 30 || shield != 0 || unit.Shields != 50 {
+		t.Errorf("Void: expected shields untouched, got %d HP / %d shield damage", hp, shield)
+	}
+}
+
+func TestRegenerateShieldsCapsAtMax(t *testing.T) {
+	unit := UnitStats{HP: 10, Shields: 8, MaxShields: 10, ShieldRegen: 5}
+	RegenerateShields(&unit)
+	if unit.Shields != 10 {
+		t.Errorf("Expected shields capped at 10, got %d", unit.Shields)
+	}
+}
@@ -0,0 +1,268 @@
+package combat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSquadMaxSize     = 4
+	defaultSquadSplashRatio = 0.5
+)
+
+// UnitRef points at one unit in a session. Index 0 is the side's lead (PlayerStats / EnemyStats),
+// 1..n are the members of its squad.
+type UnitRef struct {
+	Side  Side `json:"side"`
+	Index int  `json:"index"`
+}
+
+var (
+	playerLead = UnitRef{Side: SidePlayer, Index: 0}
+	enemyLead  = UnitRef{Side: SideEnemy, Index: 0}
+)
+
+// SplashHit is the area damage an Explosive hit dealt to one of the target's squadmates
+type SplashHit struct {
+	Target       UnitRef `json:"target"`
+	Damage       int     `json:"damage"`
+	ShieldDamage int     `json:"shield_damage,omitempty"`
+}
+
+// PlayerCommand is the player's order for one turn
+type PlayerCommand struct {
+	WeaponID   *uuid.UUID `json:"weapon_id,omitempty"` // Equipped weapon to fire; nil = unarmed attack with DamageType
+	DamageType DamageType `json:"damage_type"`
+	Target     int        `json:"target"` // Enemy index; a fallen or unknown target falls back to the first enemy standing
+}
+
+func opponent(side Side) Side {
+	if side == SidePlayer {
+		return SideEnemy
+	}
+	return SidePlayer
+}
+
+// MaxSquadSize is the number of units allowed per side, lead included
+func MaxSquadSize() int {
+	if Balance().Squads.MaxSize > 0 {
+		return Balance().Squads.MaxSize
+	}
+	return defaultSquadMaxSize
+}
+
+func splashRatio(balance *BalanceConfig) float64 {
+	if balance.Squads.SplashRatio > 0 {
+		return balance.Squads.SplashRatio
+	}
+	return defaultSquadSplashRatio
+}
+
+// Unit returns the unit a ref points at, or nil if there is none
+func (c *CombatSession) Unit(ref UnitRef) *UnitStats {
+	lead, squad := &c.PlayerStats, c.PlayerSquad
+	if ref.Side == SideEnemy {
+		lead, squad = &c.EnemyStats, c.EnemySquad
+	}
+	switch {
+	case ref.Index == 0:
+		return lead
+	case ref.Index > 0 && ref.Index <= len(squad):
+		return &squad[ref.Index-1]
+	}
+	return nil
+}
+
+// UnitCount returns how many units fight on a side, lead included
+func (c *CombatSession) UnitCount(side Side) int {
+	if side == SideEnemy {
+		return 1 + len(c.EnemySquad)
+	}
+	return 1 + len(c.PlayerSquad)
+}
+
+// Standing lists the units of a side that still have HP, in index order
+func (c *CombatSession) Standing(side Side) []UnitRef {
+	var refs []UnitRef
+	for i := 0; i < c.UnitCount(side); i++ {
+		ref := UnitRef{Side: side, Index: i}
+		if c.Unit(ref).HP > 0 {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// AddUnit adds a unit to a side's squad and returns its index
+func (s *Service) AddUnit(session *CombatSession, side Side, unit UnitStats) int {
+	if side == SideEnemy {
+		session.EnemySquad = append(session.EnemySquad, unit)
+		session.StartEnemySquad = append(session.StartEnemySquad, unit)
+		return len(session.EnemySquad)
+	}
+	session.PlayerSquad = append(session.PlayerSquad, unit)
+	session.StartPlayerSquad = append(session.StartPlayerSquad, unit)
+	return len(session.PlayerSquad)
+}
+
+// Initiative returns the acting order for a turn across every unit of both sides.
+// Higher Speed acts first; ties go to the player side, then to the lower index.
+func Initiative(session *CombatSession) []UnitRef {
+	var order []UnitRef
+	for _, side := range []Side{SidePlayer, SideEnemy} {
+		for i := 0; i < session.UnitCount(side); i++ {
+			order = append(order, UnitRef{Side: side, Index: i})
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return EffectiveStats(*session.Unit(order[i])).Speed > EffectiveStats(*session.Unit(order[j])).Speed
+	})
+	return order
+}
+
+// weakestStanding is the AI's target pick: the standing unit with the least HP
+func weakestStanding(session *CombatSession, side Side) (UnitRef, bool) {
+	standing := session.Standing(side)
+	if len(standing) == 0 {
+		return UnitRef{}, false
+	}
+	best := standing[0]
+	for _, ref := range standing[1:] {
+		if session.Unit(ref).HP < session.Unit(best).HP {
+			best = ref
+		}
+	}
+	return best, true
+}
+
+// chooseAction decides target, damage type and weapon for the acting unit: the player lead follows the
+// command, wingmen and enemies pick the weakest opponent and counter its shields
+func (s *Service) chooseAction(session *CombatSession, actor UnitRef, cmd PlayerCommand) (UnitRef, DamageType, *Weapon, bool) {
+	unit := session.Unit(actor)
+
+	if actor == playerLead {
+		target := UnitRef{Side: SideEnemy, Index: cmd.Target}
+		if t := session.Unit(target); t == nil || t.HP <= 0 {
+			standing := session.Standing(SideEnemy)
+			if len(standing) == 0 {
+				return UnitRef{}, "", nil, false
+			}
+			target = standing[0]
+		}
+		dmgType := cmd.DamageType
+		if dmgType == "" {
+			dmgType = Kinetic
+		}
+		if cmd.WeaponID != nil {
+			if weapon := findWeapon(unit, *cmd.WeaponID); weapon != nil {
+				return target, weapon.DamageType, weapon, true
+			}
+			// A force_eject earlier this turn swapped in the on-foot stats: fire the suit's weapon or go unarmed
+			if weapon := pickWeapon(unit, cmd.DamageType, session.TurnCount); weapon != nil {
+				return target, weapon.DamageType, weapon, true
+			}
+			return target, session.usableType(dmgType), nil, true
+		}
+		return target, dmgType, nil, true
+	}
+
+	target, ok := weakestStanding(session, opponent(actor.Side))
+	if !ok {
+		return UnitRef{}, "", nil, false
+	}
+	dmgType := session.usableType(counterType(session.Unit(target)))
+	if actor.Side == SideEnemy {
+		dmgType = s.enemyActionAgainst(session, session.Unit(target))
+	}
+	if weapon := pickWeapon(unit, s.preferredType(session, actor.Side, session.Unit(target)), session.TurnCount); weapon != nil {
+		return target, weapon.DamageType, weapon, true
+	}
+	return target, dmgType, nil, true
+}
+
+// preferredType is the damage type an armed unit should favour against target: Energy into shields,
+// a script-forced type for enemies, otherwise none (strongest weapon wins)
+func (s *Service) preferredType(session *CombatSession, side Side, target *UnitStats) DamageType {
+	if side == SideEnemy && session.EnemyDamageType != "" {
+		return session.EnemyDamageType
+	}
+	if target.Shields > 0 {
+		return Energy
+	}
+	return ""
+}
+
+// AutoCommand plays the player lead like a wingman: focus the weakest enemy with the best usable weapon
+func (s *Service) AutoCommand(session *CombatSession) PlayerCommand {
+	target, ok := weakestStanding(session, SideEnemy)
+	if !ok {
+		return PlayerCommand{DamageType: Kinetic}
+	}
+	preferred := s.preferredType(session, SidePlayer, session.Unit(target))
+
+	// Energy regenerates before the lead acts, so plan against next turn's pool
+	next := session.PlayerStats
+	RegenerateEnergy(&next)
+	if weapon := pickWeapon(&next, preferred, session.TurnCount+1); weapon != nil {
+		id := weapon.ItemID
+		return PlayerCommand{WeaponID: &id, Target: target.Index}
+	}
+	return PlayerCommand{DamageType: session.usableType(counterType(session.Unit(target))), Target: target.Index}
+}
+
+// CommandFor turns a driver's damage type pick into a command for the player lead: an armed lead fires its
+// best ready weapon, favouring dmgType, and only a lead with nothing ready attacks unarmed
+func (s *Service) CommandFor(session *CombatSession, dmgType DamageType) PlayerCommand {
+	// Energy regenerates before the lead acts, so plan against next turn's pool
+	next := session.PlayerStats
+	RegenerateEnergy(&next)
+	if weapon := pickWeapon(&next, dmgType, session.TurnCount+1); weapon != nil {
+		id := weapon.ItemID
+		return PlayerCommand{WeaponID: &id}
+	}
+	return PlayerCommand{DamageType: dmgType}
+}
+
+// counterType answers raised shields with Energy and otherwise fires Kinetic
+func counterType(target *UnitStats) DamageType {
+	if target.Shields > 0 {
+		return Energy
+	}
+	return Kinetic
+}
+
+// applySplash deals the area part of an Explosive hit to every other standing unit on the target's side
+func applySplash(balance *BalanceConfig, session *CombatSession, target UnitRef, damage int) []SplashHit {
+	splashDamage := int(float64(damage) * splashRatio(balance))
+	if splashDamage <= 0 {
+		return nil
+	}
+
+	var hits []SplashHit
+	for _, ref := range session.Standing(target.Side) {
+		if ref == target {
+			continue
+		}
+		unit := session.Unit(ref)
+		hpDamage, shieldDamage := AbsorbDamage(balance, unit, splashDamage, Explosive)
+		unit.HP -= hpDamage
+		if unit.HP < 0 {
+			unit.HP = 0
+		}
+		hits = append(hits, SplashHit{Target: ref, Damage: hpDamage, ShieldDamage: shieldDamage})
+	}
+	return hits
+}
+
+func unitName(ref UnitRef) string {
+	name := "Player"
+	if ref.Side == SideEnemy {
+		name = "Enemy"
+	}
+	if ref.Index > 0 {
+		return fmt.Sprintf("%s #%d", name, ref.Index+1)
+	}
+	return name
+}
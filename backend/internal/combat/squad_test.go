@@ -0,0 +1,97 @@
+package combat
+
+import "testing"
+
+func squadSession(service *Service) *CombatSession {
+	player := UnitStats{HP: 500, MaxHP: 500, BaseAttack: 40, Accuracy: 100, Speed: 50, IsPlayer: true}
+	enemy := UnitStats{HP: 200, MaxHP: 200, BaseAttack: 10, Accuracy: 100, Speed: 10}
+	session := service.NewSession(player, enemy, 5)
+	service.AddUnit(session, SideEnemy, enemy)
+	service.AddUnit(session, SideEnemy, enemy)
+	return session
+}
+
+func TestInitiativeCoversSquads(t *testing.T) {
+	service := NewService(NewEngine())
+	session := squadSession(service)
+	service.AddUnit(session, SidePlayer, UnitStats{HP: 100, MaxHP: 100, Speed: 5})
+
+	order := Initiative(session)
+	if len(order) != 5 {
+		t.Fatalf("Expected 5 units in initiative, got %d", len(order))
+	}
+	if order[0] != playerLead || order[len(order)-1] != (UnitRef{Side: SidePlayer, Index: 1}) {
+		t.Errorf("Expected fast lead first and slow wingman last, got %v", order)
+	}
+}
+
+func TestPlayCommandHitsChosenTarget(t *testing.T) {
+	service := NewService(NewEngine())
+	session := squadSession(service)
+
+	events, _ := service.PlayCommand(session, PlayerCommand{DamageType: Kinetic, Target: 2})
+
+	if events[0].Actor != SidePlayer || events[0].TargetIndex != 2 {
+		t.Fatalf("Expected the player to hit enemy 2, got %+v", events[0])
+	}
+	if !events[0].Result.IsMiss && session.EnemySquad[1].HP >= 200 {
+		t.Error("Targeted enemy should have taken damage")
+	}
+	if session.EnemyStats.HP != 200 || session.EnemySquad[0].HP != 200 {
+		t.Error("Kinetic hits should not touch other enemies")
+	}
+	if len(events) != 4 {
+		t.Errorf("Expected the player and all three enemies to act, got %d events", len(events))
+	}
+}
+
+func TestExplosiveSplashesSquad(t *testing.T) {
+	service := NewService(NewEngine())
+	session := squadSession(service)
+	session.EnemyStats.HP = 0 // Fallen units take no splash
+
+	result := service.resolveAttack(session, playerLead, UnitRef{Side: SideEnemy, Index: 1}, Explosive, nil)
+	if result.IsMiss {
+		t.Skip("Seeded roll missed")
+	}
+
+	if len(result.Splash) != 1 || result.Splash[0].Target.Index != 2 {
+		t.Fatalf("Expected splash on enemy 2 only, got %+v", result.Splash)
+	}
+	if want := 200 - int(float64(result.FinalDamage)*splashRatio(Balance())); session.EnemySquad[1].HP != want {
+		t.Errorf("Expected splash to leave %d HP, got %d", want, session.EnemySquad[1].HP)
+	}
+}
+
+func TestSquadVictoryNeedsEveryEnemyDown(t *testing.T) {
+	service := NewService(NewEngine())
+	session := squadSession(service)
+
+	session.EnemyStats.HP = 0
+	session.EnemySquad[0].HP = 0
+	if service.checkOutcome(session) != OutcomeOngoing {
+		t.Fatal("One enemy is still standing")
+	}
+
+	// A fallen target falls back to the first standing enemy
+	target, _, _, _ := service.chooseAction(session, playerLead, PlayerCommand{Target: 0})
+	if target.Index != 2 {
+		t.Errorf("Expected fallback to enemy 2, got %d", target.Index)
+	}
+
+	session.EnemySquad[1].HP = 0
+	if service.checkOutcome(session) != OutcomeVictory {
+		t.Error("Expected victory with the whole squad down")
+	}
+}
+
+func TestEnemyAIFocusesWeakestUnit(t *testing.T) {
+	service := NewService(NewEngine())
+	session := squadSession(service)
+	service.AddUnit(session, SidePlayer, UnitStats{HP: 50, MaxHP: 100})
+
+	target, _, _, ok := service.chooseAction(session, enemyLead, PlayerCommand{})
+	if !ok || target != (UnitRef{Side: SidePlayer, Index: 1}) {
+		t.Errorf("Enemy should focus the damaged wingman, got %v", target)
+	}
+}
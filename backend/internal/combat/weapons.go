@@ -0,0 +1,142 @@
+package combat
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
+)
+
+const (
+	defaultWeaponEnergy      = 100
+	defaultWeaponEnergyRegen = 20
+)
+
+// defaultWeaponCooldowns are used when the balance file has no cooldown for a damage type
+var defaultWeaponCooldowns = map[DamageType]int{
+	Kinetic:   0,
+	Energy:    1,
+	Explosive: 2,
+	Void:      3,
+}
+
+// Weapon is an equipped item a unit can fire. Its damage type and attack come from the item.
+type Weapon struct {
+	ItemID     uuid.UUID  `json:"item_id"`
+	Name       string     `json:"name"`
+	DamageType DamageType `json:"damage_type"`
+	Attack     int        `json:"attack"`      // Added to the unit's BaseAttack for this shot
+	EnergyCost int        `json:"energy_cost"` // ItemStats.EnergyConsume
+	Cooldown   int        `json:"cooldown"`    // Turns the weapon must sit out after firing
+	LastFired  int        `json:"last_fired,omitempty"` // Turn it last fired on (0 = never)
+	Disabled   bool       `json:"disabled,omitempty"`   // Knocked out by the fight's hazard
+}
+
+// Ready reports whether the weapon has cooled down by the given turn
+func (w Weapon) Ready(turn int) bool {
+	return w.LastFired == 0 || turn-w.LastFired > w.Cooldown
+}
+
+// WeaponFromItem turns an equipped item with a damage type into a weapon; other items return false
+func WeaponFromItem(item vehicle.Item) (Weapon, bool) {
+	if !item.IsEquipped || item.DamageType == nil || *item.DamageType == "" {
+		return Weapon{}, false
+	}
+
+	dmgType := DamageType(*item.DamageType)
+	cooldown, ok := Balance().Weapons.Cooldowns[dmgType]
+	if !ok {
+		cooldown = defaultWeaponCooldowns[dmgType]
+	}
+	// Per-item override from metadata {"cooldown": n}
+	if meta, ok := item.Metadata.(map[string]interface{}); ok {
+		if c, ok := meta["cooldown"].(float64); ok && c >= 0 {
+			cooldown = int(c)
+		}
+	}
+
+	return Weapon{
+		ItemID:     item.ID,
+		Name:       item.Name,
+		DamageType: dmgType,
+		Attack:     item.Stats.Attack,
+		EnergyCost: item.Stats.EnergyConsume,
+		Cooldown:   cooldown,
+	}, true
+}
+
+// weaponEnergy returns the energy pool and per-turn regeneration for armed units
+func weaponEnergy() (int, int) {
+	pool, regen := Balance().Weapons.BaseEnergy, Balance().Weapons.EnergyRegen
+	if pool <= 0 {
+		pool = defaultWeaponEnergy
+	}
+	if regen <= 0 {
+		regen = defaultWeaponEnergyRegen
+	}
+	return pool, regen
+}
+
+// RegenerateEnergy restores the unit's weapon energy at the start of its turn
+func RegenerateEnergy(unit *UnitStats) {
+	if unit.EnergyRegen <= 0 || unit.Energy >= unit.MaxEnergy {
+		return
+	}
+	unit.Energy += unit.EnergyRegen
+	if unit.Energy > unit.MaxEnergy {
+		unit.Energy = unit.MaxEnergy
+	}
+}
+
+// findWeapon returns the unit's weapon for an item ID
+func findWeapon(unit *UnitStats, itemID uuid.UUID) *Weapon {
+	for i := range unit.Weapons {
+		if unit.Weapons[i].ItemID == itemID {
+			return &unit.Weapons[i]
+		}
+	}
+	return nil
+}
+
+// checkWeapon reports why a weapon cannot fire this turn
+func checkWeapon(unit *UnitStats, weapon *Weapon, turn int) error {
+	if weapon.Disabled {
+		return fmt.Errorf("%s is disabled by the hazard", weapon.Name)
+	}
+	if !weapon.Ready(turn) {
+		return fmt.Errorf("%s is cooling down", weapon.Name)
+	}
+	if weapon.EnergyCost > unit.Energy {
+		return fmt.Errorf("not enough energy for %s (%d/%d)", weapon.Name, unit.Energy, weapon.EnergyCost)
+	}
+	return nil
+}
+
+// pickWeapon is the AI's weapon choice: a usable weapon of the preferred type (if any), else the strongest usable one.
+// It returns nil when nothing can fire, in which case the unit attacks unarmed.
+func pickWeapon(unit *UnitStats, preferred DamageType, turn int) *Weapon {
+	var best *Weapon
+	for i := range unit.Weapons {
+		w := &unit.Weapons[i]
+		if checkWeapon(unit, w, turn) != nil {
+			continue
+		}
+		switch {
+		case best == nil:
+			best = w
+		case (w.DamageType == preferred) != (best.DamageType == preferred):
+			if w.DamageType == preferred {
+				best = w
+			}
+		case w.Attack > best.Attack:
+			best = w
+		}
+	}
+	return best
+}
+
+// fire spends the weapon's energy and starts its cooldown
+func fire(unit *UnitStats, weapon *Weapon, turn int) {
+	unit.Energy -= weapon.EnergyCost
+	weapon.LastFired = turn
+}
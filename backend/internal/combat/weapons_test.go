@@ -0,0 +1,171 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
+)
+
+func weaponItem(dmgType string, attack, energy int, metadata interface{}) vehicle.Item {
+	return vehicle.Item{
+		ID:         uuid.New(),
+		Name:       dmgType + " Cannon",
+		DamageType: &dmgType,
+		IsEquipped: true,
+		Stats:      vehicle.ItemStats{Attack: attack, EnergyConsume: energy},
+		Metadata:   metadata,
+	}
+}
+
+func TestWeaponFromItem(t *testing.T) {
+	if _, ok := WeaponFromItem(vehicle.Item{IsEquipped: true}); ok {
+		t.Error("Items without a damage type are not weapons")
+	}
+
+	w, ok := WeaponFromItem(weaponItem("EXPLOSIVE", 30, 40, nil))
+	if !ok || w.DamageType != Explosive || w.Attack != 30 || w.EnergyCost != 40 {
+		t.Fatalf("Unexpected weapon %+v", w)
+	}
+	if w.Cooldown != defaultWeaponCooldowns[Explosive] {
+		t.Errorf("Expected default Explosive cooldown, got %d", w.Cooldown)
+	}
+
+	w, _ = WeaponFromItem(weaponItem("ENERGY", 10, 0, map[string]interface{}{"cooldown": float64(4)}))
+	if w.Cooldown != 4 {
+		t.Errorf("Metadata cooldown should override the default, got %d", w.Cooldown)
+	}
+}
+
+func armedSession(service *Service, weapons ...vehicle.Item) *CombatSession {
+	v := &vehicle.Vehicle{Stats: vehicle.VehicleStats{HP: 1000, Attack: 10, Defense: 5, Speed: 50}}
+	player := service.MapVehicleToUnitStats(v, weapons, nil)
+	player.IsPlayer = true
+	player.Accuracy = 100
+	enemy := UnitStats{HP: 1000, MaxHP: 1000, BaseAttack: 5, Accuracy: 100, Speed: 10}
+	return service.NewSession(player, enemy, 3)
+}
+
+func TestPlayCommandFiresWeapon(t *testing.T) {
+	service := NewService(NewEngine())
+	cannon := weaponItem("EXPLOSIVE", 50, 60, nil)
+	session := armedSession(service, cannon)
+
+	events, err := service.PlayCommand(session, PlayerCommand{WeaponID: &cannon.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].DamageType != Explosive || events[0].WeaponID == nil || *events[0].WeaponID != cannon.ID {
+		t.Errorf("Expected an Explosive shot from the cannon, got %+v", events[0])
+	}
+	if session.PlayerStats.Energy != session.PlayerStats.MaxEnergy-60 {
+		t.Errorf("Expected energy to drop by 60, got %d", session.PlayerStats.Energy)
+	}
+
+	// Explosive weapons sit out two turns by default
+	if _, err := service.PlayCommand(session, PlayerCommand{WeaponID: &cannon.ID}); err == nil {
+		t.Error("Weapon on cooldown should be rejected")
+	}
+	if session.TurnCount != 1 {
+		t.Errorf("A rejected command must not play a turn, TurnCount %d", session.TurnCount)
+	}
+}
+
+func TestPlayCommandRejectsUnknownAndCostlyWeapons(t *testing.T) {
+	service := NewService(NewEngine())
+	beam := weaponItem("ENERGY", 10, 500, nil)
+	session := armedSession(service, beam)
+
+	other := uuid.New()
+	if _, err := service.PlayCommand(session, PlayerCommand{WeaponID: &other}); err == nil {
+		t.Error("Weapons not on the vehicle should be rejected")
+	}
+	if _, err := service.PlayCommand(session, PlayerCommand{WeaponID: &beam.ID}); err == nil {
+		t.Error("Weapons costing more than the energy pool should be rejected")
+	}
+}
+
+func TestPlayCommandRequiresReadyWeaponWhenArmed(t *testing.T) {
+	service := NewService(NewEngine())
+	cannon := weaponItem("EXPLOSIVE", 50, 60, nil)
+	session := armedSession(service, cannon)
+
+	if _, err := service.PlayCommand(session, PlayerCommand{DamageType: Void}); err == nil {
+		t.Error("Armed units should not pick a damage type without a weapon")
+	}
+
+	// Drivers pick from the lead's weapons, and attack unarmed only while nothing can fire
+	events, err := service.PlayCommand(session, service.CommandFor(session, Kinetic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].WeaponID == nil || *events[0].WeaponID != cannon.ID {
+		t.Errorf("Expected the cannon to fire, got %+v", events[0])
+	}
+	cmd := service.CommandFor(session, Kinetic)
+	if cmd.WeaponID != nil {
+		t.Fatalf("Expected an unarmed command while the cannon cools down, got %+v", cmd)
+	}
+	if _, err := service.PlayCommand(session, cmd); err != nil {
+		t.Errorf("Unarmed attacks are allowed while no weapon is ready: %v", err)
+	}
+}
+
+func TestPickWeaponPrefersCounterType(t *testing.T) {
+	unit := &UnitStats{Energy: 100, Weapons: []Weapon{
+		{Name: "Gun", DamageType: Kinetic, Attack: 40},
+		{Name: "Beam", DamageType: Energy, Attack: 20},
+		{Name: "Lance", DamageType: Energy, Attack: 30, EnergyCost: 200},
+	}}
+
+	if w := pickWeapon(unit, Energy, 1); w == nil || w.Name != "Beam" {
+		t.Errorf("Expected the affordable Energy weapon, got %+v", w)
+	}
+	if w := pickWeapon(unit, Void, 1); w == nil || w.Name != "Gun" {
+		t.Errorf("Expected the strongest usable weapon, got %+v", w)
+	}
+}
+
+func TestAutoCommandUsesWeapons(t *testing.T) {
+	service := NewService(NewEngine())
+	cannon := weaponItem("EXPLOSIVE", 50, 10, nil)
+	gun := weaponItem("KINETIC", 5, 0, nil)
+	session := armedSession(service, gun, cannon)
+
+	summary := service.RunCommands(session, service.AutoCommand, 30)
+
+	fired := map[DamageType]bool{}
+	for _, ev := range summary.Events {
+		if ev.Actor == SidePlayer && ev.WeaponID != nil {
+			fired[ev.DamageType] = true
+		}
+	}
+	if !fired[Explosive] || !fired[Kinetic] {
+		t.Errorf("Auto-pilot should alternate weapons around cooldowns, fired %v", fired)
+	}
+}
+
+func TestExecuteAttackRequiresEquippedWeapon(t *testing.T) {
+	service := NewService(NewEngine())
+	cannon := weaponItem("EXPLOSIVE", 50, 60, nil)
+	session := armedSession(service, cannon)
+
+	if _, err := service.ExecuteAttack(session, PlayerCommand{DamageType: Void}); err == nil {
+		t.Error("Armed units should not pick a damage type without a weapon")
+	}
+	other := uuid.New()
+	if _, err := service.ExecuteAttack(session, PlayerCommand{WeaponID: &other}); err == nil {
+		t.Error("Weapons not on the unit should be rejected")
+	}
+
+	energy := session.PlayerStats.Energy
+	if _, err := service.ExecuteAttack(session, PlayerCommand{WeaponID: &cannon.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if session.PlayerStats.Energy != energy-60 {
+		t.Errorf("Expected the shot to spend 60 energy, got %d -> %d", energy, session.PlayerStats.Energy)
+	}
+	if session.EnemyStats.HP >= 1000 {
+		t.Error("Expected the cannon to damage the enemy")
+	}
+}
@@ -0,0 +1,48 @@
+package exploration
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// AdminHandler exposes operator-only exploration tools
+type AdminHandler struct {
+	service    *Service
+	adminToken string
+}
+
+func NewAdminHandler(service *Service, adminToken string) *AdminHandler {
+	return &AdminHandler{service: service, adminToken: adminToken}
+}
+
+// RegenerateTimeline rebuilds an expedition's timeline from its stored seed and diffs it against the stored nodes.
+// Nothing is written. Requires the X-Admin-Token header; disabled when no token is configured.
+func (h *AdminHandler) RegenerateTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := r.Header.Get("X-Admin-Token")
+	if h.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	expeditionID, err := uuid.Parse(r.URL.Query().Get("expedition_id"))
+	if err != nil {
+		http.Error(w, "Invalid expedition_id", http.StatusBadRequest)
+		return
+	}
+
+	audit, err := h.service.RegenerateTimeline(r.Context(), expeditionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(audit)
+}
@@ -0,0 +1,119 @@
+package exploration
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// ApproachLoud marks choices that draw attention whether or not the pilot is detected
+const ApproachLoud ApproachType = "LOUD"
+
+// AlarmTier is how aware the sector is of the expedition, derived from Expedition.AlarmLevel
+type AlarmTier string
+
+const (
+	AlarmCalm       AlarmTier = "CALM"
+	AlarmSuspicious AlarmTier = "SUSPICIOUS"
+	AlarmAlert      AlarmTier = "ALERT"
+	AlarmLockdown   AlarmTier = "LOCKDOWN"
+)
+
+const (
+	maxAlarmLevel       = 100
+	alarmDetectionRise  = 25 // Signature above the node's threshold
+	alarmLoudRise       = 15 // LOUD choice
+	alarmStealthDecay   = 20 // Undetected STEALTH choice
+	stealthSignatureMod = 0.7
+)
+
+// alarmTierFloors are the lowest AlarmLevel of each tier, highest first
+var alarmTierFloors = []struct {
+	Tier  AlarmTier
+	Floor int
+}{
+	{AlarmLockdown, 75},
+	{AlarmAlert, 50},
+	{AlarmSuspicious, 25},
+	{AlarmCalm, 0},
+}
+
+// alarmReinforcements are the extra enemies joining each fight per tier
+var alarmReinforcements = map[AlarmTier]int{
+	AlarmAlert:    1,
+	AlarmLockdown: 2,
+}
+
+// escalatableNodes are the node types a raised alarm can turn into a fight
+var escalatableNodes = map[NodeType]bool{
+	NodeStandard:  true,
+	NodeResource:  true,
+	NodeAnomaly:   true,
+	NodeNarrative: true,
+}
+
+// AlarmTierFor maps an alarm level to its tier
+func AlarmTierFor(level int) AlarmTier {
+	for _, t := range alarmTierFloors {
+		if level >= t.Floor {
+			return t.Tier
+		}
+	}
+	return AlarmCalm
+}
+
+// AlarmTier is the expedition's current alarm tier
+func (e *Expedition) AlarmTier() AlarmTier {
+	return AlarmTierFor(e.AlarmLevel)
+}
+
+// NextAlarmLevel applies one node resolution to the alarm: detection and LOUD choices raise it,
+// an undetected STEALTH choice lets it decay
+func NextAlarmLevel(level int, approach ApproachType, detected bool) int {
+	if detected {
+		level += alarmDetectionRise
+	}
+	switch {
+	case approach == ApproachLoud:
+		level += alarmLoudRise
+	case approach == ApproachStealth && !detected:
+		level -= alarmStealthDecay
+	}
+	if level < 0 {
+		return 0
+	}
+	if level > maxAlarmLevel {
+		return maxAlarmLevel
+	}
+	return level
+}
+
+// alarmDifficulty scales node difficulty with the standing alarm (up to 1.5x at full alarm)
+func alarmDifficulty(level int) float64 {
+	return 1.0 + float64(level)/200.0
+}
+
+// alarmThreshold lowers a node's detection threshold as the sector grows watchful (down to half)
+func alarmThreshold(threshold, level int) int {
+	return int(float64(threshold) * (1.0 - float64(level)/200.0))
+}
+
+// escalatesToCombat decides whether the alarm turns a quiet node into a fight. From ALERT on, the chance
+// grows with the level; the roll is seeded by the node, so a higher alarm never un-escalates a node.
+func escalatesToCombat(node *Node, nodes []Node, level int) bool {
+	if AlarmTierFor(level) != AlarmAlert && AlarmTierFor(level) != AlarmLockdown {
+		return false
+	}
+	if !escalatableNodes[node.Type] || node.IsScripted || isFinalNode(node, nodes) {
+		return false
+	}
+	rng := rand.New(rand.NewSource(alarmSeed(node)))
+	return rng.Float64() < float64(level)/float64(maxAlarmLevel)-0.25
+}
+
+// alarmSeed derives the node's escalation roll, independent of the loot seeds
+func alarmSeed(node *Node) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("alarm-escalation:"))
+	h.Write(node.ID[:])
+	return int64(h.Sum64())
+}
@@ -0,0 +1,87 @@
+package exploration
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// EnemyInstance is a concrete enemy spawned for one encounter from a game.EnemyBlueprint.
+// Its stats are scaled at spawn time and its HP persists between battles.
+type EnemyInstance struct {
+	ID           uuid.UUID `json:"id"`
+	ExpeditionID uuid.UUID `json:"expedition_id"`
+	EncounterID  uuid.UUID `json:"encounter_id"`
+	BlueprintID  string    `json:"blueprint_id"`
+	Name         string    `json:"name"`
+	Scale        float64   `json:"scale"`
+	HP           int       `json:"hp"`
+	MaxHP        int       `json:"max_hp"`
+	Attack       int       `json:"attack"`
+	Defense      int       `json:"defense"`
+	Speed        int       `json:"speed"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsDefeated reports whether the enemy has no HP left
+func (e *EnemyInstance) IsDefeated() bool {
+	return e.HP <= 0
+}
+
+// expeditionDifficultyStep is the extra scale per expedition difficulty level above 1
+const expeditionDifficultyStep = 0.25
+
+// EnemyScale combines the node's DifficultyMultiplier, its hazard effect (may be nil) and the expedition difficulty
+func EnemyScale(node *Node, expeditionDifficulty int, hazard *game.HazardEffect) float64 {
+	scale := 1.0
+	if node != nil && node.DifficultyMultiplier > 0 {
+		scale *= node.DifficultyMultiplier
+	}
+	if hazard != nil && hazard.EnemyScale > 0 {
+		scale *= hazard.EnemyScale
+	}
+	if expeditionDifficulty > 1 {
+		scale *= 1 + expeditionDifficultyStep*float64(expeditionDifficulty-1)
+	}
+	return scale
+}
+
+// NewEnemyInstance spawns a full-HP enemy from a blueprint. HP, attack and defense scale; speed does not,
+// so turn order stays readable from the blueprint.
+func NewEnemyInstance(bp game.EnemyBlueprint, expeditionID, encounterID uuid.UUID, scale float64) *EnemyInstance {
+	scaled := func(v int) int {
+		return int(math.Round(float64(v) * scale))
+	}
+	hp := scaled(bp.Stats.HP)
+	if hp < 1 {
+		hp = 1
+	}
+	now := time.Now()
+	return &EnemyInstance{
+		ID:           uuid.New(),
+		ExpeditionID: expeditionID,
+		EncounterID:  encounterID,
+		BlueprintID:  bp.ID,
+		Name:         bp.Name,
+		Scale:        scale,
+		HP:           hp,
+		MaxHP:        hp,
+		Attack:       scaled(bp.Stats.Attack),
+		Defense:      scaled(bp.Stats.Defense),
+		Speed:        bp.Stats.Speed,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Blueprint returns the blueprint with this instance's scaled stats (HP is the maximum)
+func (e *EnemyInstance) Blueprint(bp game.EnemyBlueprint) game.EnemyBlueprint {
+	bp.Stats.HP = e.MaxHP
+	bp.Stats.Attack = e.Attack
+	bp.Stats.Defense = e.Defense
+	bp.Stats.Speed = e.Speed
+	return bp
+}
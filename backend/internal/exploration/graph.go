@@ -0,0 +1,93 @@
+package exploration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBranchChoiceRequired = errors.New("the current node branches; choose the next node")
+	ErrInvalidNextNode      = errors.New("next node is not reachable from the current node")
+)
+
+// isGraphTimeline reports whether the nodes form a branching graph (handcrafted expeditions)
+// rather than a linear, procedurally generated timeline
+func isGraphTimeline(nodes []Node) bool {
+	for _, n := range nodes {
+		if len(n.NextNodes) > 0 || n.IsEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinalNode reports whether reaching node ends the timeline: an IsEnd node, or the last node of a linear timeline
+func isFinalNode(node *Node, nodes []Node) bool {
+	if node.IsEnd {
+		return true
+	}
+	return !isGraphTimeline(nodes) && len(nodes) > 0 && nodes[len(nodes)-1].ID == node.ID
+}
+
+// nodeByID finds a node of the expedition
+func nodeByID(nodes []Node, id uuid.UUID) *Node {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+// selectNextNode picks the node the next encounter happens at. Linear timelines walk by position;
+// graphs start at the first node and then follow the current node's NextNodes, where choice (a
+// blueprint node ID) is required only when there is more than one way forward.
+// A nil node means the linear timeline is exhausted.
+func selectNextNode(nodes []Node, encounters []Encounter, choice string) (*Node, error) {
+	if !isGraphTimeline(nodes) {
+		if len(encounters) >= len(nodes) {
+			return nil, nil
+		}
+		return &nodes[len(encounters)], nil
+	}
+
+	if len(encounters) == 0 {
+		return &nodes[0], nil
+	}
+	last := encounters[len(encounters)-1]
+	if last.NodeID == nil {
+		return nil, fmt.Errorf("current encounter has no node")
+	}
+	current := nodeByID(nodes, *last.NodeID)
+	if current == nil {
+		return nil, fmt.Errorf("current node %s not found", last.NodeID)
+	}
+	if current.IsEnd {
+		return nil, ErrExpeditionExtracting
+	}
+
+	if choice == "" {
+		if len(current.NextNodes) != 1 {
+			return nil, ErrBranchChoiceRequired
+		}
+		choice = current.NextNodes[0]
+	}
+	allowed := false
+	for _, id := range current.NextNodes {
+		if id == choice {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, ErrInvalidNextNode
+	}
+	for i := range nodes {
+		if nodes[i].BlueprintID == choice {
+			return &nodes[i], nil
+		}
+	}
+	return nil, ErrInvalidNextNode
+}
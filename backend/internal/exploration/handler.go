@@ -1,7 +1,9 @@
 package exploration
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -47,7 +49,7 @@ func (h *Handler) ResolveNode(w http.ResponseWriter, r *http.Request) {
 
 	node, err := h.service.ResolveNode(r.Context(), req.NodeID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -68,7 +70,7 @@ func (h *Handler) ResolveChoice(w http.ResponseWriter, r *http.Request) {
 
 	node, err := h.service.ResolveNodeChoice(r.Context(), req.NodeID, req.Choice)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -169,7 +171,7 @@ func (h *Handler) StartExploration(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -215,6 +217,7 @@ func (h *Handler) AdvanceTimeline(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ExpeditionID uuid.UUID `json:"expedition_id"`
 		VehicleID    uuid.UUID `json:"vehicle_id"`
+		NextNodeID   string    `json:"next_node_id"` // Branch to take on handcrafted expeditions
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -233,9 +236,9 @@ func (h *Handler) AdvanceTimeline(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	encounter, err := h.service.GenerateNewEncounter(r.Context(), req.ExpeditionID, req.VehicleID)
+	encounter, err := h.service.GenerateNewEncounter(r.Context(), req.ExpeditionID, req.VehicleID, req.NextNodeID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
@@ -263,3 +266,84 @@ func (h *Handler) AdvanceTimeline(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// CompleteExpedition returns an extracting expedition to the Bastion
+func (h *Handler) CompleteExpedition(w http.ResponseWriter, r *http.Request) {
+	h.finishExpedition(w, r, h.service.CompleteExpedition)
+}
+
+// AbandonExpedition ends an expedition early
+func (h *Handler) AbandonExpedition(w http.ResponseWriter, r *http.Request) {
+	h.finishExpedition(w, r, h.service.AbandonExpedition)
+}
+
+func (h *Handler) finishExpedition(w http.ResponseWriter, r *http.Request, finish func(ctx context.Context, expeditionID uuid.UUID) (*Expedition, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value(constants.UserIDKey).(uuid.UUID)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ExpeditionID uuid.UUID `json:"expedition_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expedition, err := h.service.repo.GetExpeditionByID(req.ExpeditionID)
+	if err != nil {
+		http.Error(w, "Expedition not found", http.StatusNotFound)
+		return
+	}
+	if expedition.UserID != userID {
+		http.Error(w, "You do not own this expedition", http.StatusForbidden)
+		return
+	}
+
+	expedition, err = finish(r.Context(), req.ExpeditionID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	pilotStats, err := h.service.gameRepo.GetActivePilotStats(expedition.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"expedition":  expedition,
+		"pilot_stats": pilotStats,
+	})
+}
+
+// writeServiceError answers bad branch choices with 400, state machine violations with 409 Conflict
+// and anything else with 500
+func writeServiceError(w http.ResponseWriter, err error) {
+	var transitionErr *TransitionError
+	switch {
+	case errors.Is(err, ErrBranchChoiceRequired), errors.Is(err, ErrInvalidNextNode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrModeNotAllowed), errors.Is(err, ErrAtmosphericEntryRequired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.As(err, &transitionErr),
+		errors.Is(err, ErrExpeditionFinished),
+		errors.Is(err, ErrCombatInProgress),
+		errors.Is(err, ErrExpeditionExtracting),
+		errors.Is(err, ErrCombatUnresolved),
+		errors.Is(err, ErrNodeUnresolved),
+		errors.Is(err, ErrNodeAlreadyResolved):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
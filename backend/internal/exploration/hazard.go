@@ -0,0 +1,86 @@
+package exploration
+
+import (
+	"context"
+
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// hazardPool is the hazards generation may roll at the site: those listing the site's terrain (or no terrains),
+// minus atmosphere-only hazards on vacuum sites. Without a site every loaded hazard qualifies.
+func (s *Service) hazardPool(site *Site) []HazardType {
+	var pool []HazardType
+	for _, id := range s.blueprints.HazardIDs() {
+		h := s.blueprints.Hazards[id]
+		if site != nil {
+			if h.RequiresAtmosphere && !site.RequiresAtmosphere() {
+				continue
+			}
+			if len(h.Terrains) > 0 && !containsString(h.Terrains, string(site.Terrain())) {
+				continue
+			}
+		}
+		pool = append(pool, HazardType(id))
+	}
+	return pool
+}
+
+// HazardEffect resolves a node hazard for the expedition: the blueprint's modifiers after the pilot's research
+// and the counter items equipped on the expedition's vehicle (or, on foot, the pilot's exosuit).
+// It returns nil for HazardNone and hazards without a blueprint.
+func (s *Service) HazardEffect(ctx context.Context, hazard HazardType, expedition *Expedition, pilot *game.PilotStats) *game.HazardEffect {
+	if hazard == "" || hazard == HazardNone {
+		return nil
+	}
+	bp, ok := s.blueprints.Hazards[string(hazard)]
+	if !ok {
+		return nil
+	}
+
+	carrier := expedition.VehicleID
+	if carrier == nil && pilot != nil {
+		carrier = pilot.EquippedExosuitID
+	}
+	var equipped []string
+	if s.vehicleUseCase != nil && carrier != nil && hasItemCounter(bp) {
+		items, err := s.vehicleUseCase.GetItems(ctx, expedition.UserID)
+		if err == nil {
+			for _, item := range items {
+				if item.IsEquipped && item.ParentItemID != nil && *item.ParentItemID == *carrier {
+					equipped = append(equipped, item.Name)
+				}
+			}
+		}
+	}
+	return bp.Effect(bp.Mitigation(pilot, equipped))
+}
+
+// pilotHazardEffect is HazardEffect for callers that have not loaded the pilot yet
+func (s *Service) pilotHazardEffect(ctx context.Context, hazard HazardType, expedition *Expedition) *game.HazardEffect {
+	if hazard == "" || hazard == HazardNone {
+		return nil
+	}
+	var pilot *game.PilotStats
+	if s.gameRepo != nil {
+		pilot, _ = s.gameRepo.GetActivePilotStats(expedition.UserID)
+	}
+	return s.HazardEffect(ctx, hazard, expedition, pilot)
+}
+
+func hasItemCounter(bp game.HazardBlueprint) bool {
+	for _, c := range bp.Counters {
+		if c.Item != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -22,8 +22,8 @@ func (r *explorationRepository) CreateNodes(nodes []Node) error {
 	}
 	defer tx.Rollback()
 
-	query := `INSERT INTO nodes (id, expedition_id, name, type, zone, hazard, environment_description, difficulty_multiplier, position_index, choices, is_resolved, terrain, detection_threshold, next_nodes, is_scripted, script_events, is_end, enemy_blueprint, enemy_count) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
+	query := `INSERT INTO nodes (id, expedition_id, name, type, zone, hazard, environment_description, difficulty_multiplier, position_index, choices, is_resolved, terrain, detection_threshold, next_nodes, is_scripted, script_events, is_end, enemy_blueprint, enemy_count, blueprint_id) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`
 
 	for _, n := range nodes {
 		choicesJSON, err := json.Marshal(n.Choices)
@@ -34,7 +34,7 @@ func (r *explorationRepository) CreateNodes(nodes []Node) error {
 		if err != nil {
 			return err
 		}
-		_, err = tx.Exec(query, n.ID, n.ExpeditionID, n.Name, n.Type, n.Zone, n.Hazard, n.EnvironmentDescription, n.DifficultyMultiplier, n.PositionIndex, choicesJSON, n.IsResolved, n.Terrain, n.DetectionThreshold, pq.Array(n.NextNodes), n.IsScripted, scriptEventsJSON, n.IsEnd, n.EnemyBlueprint, n.EnemyCount)
+		_, err = tx.Exec(query, n.ID, n.ExpeditionID, n.Name, n.Type, n.Zone, n.Hazard, n.EnvironmentDescription, n.DifficultyMultiplier, n.PositionIndex, choicesJSON, n.IsResolved, n.Terrain, n.DetectionThreshold, pq.Array(n.NextNodes), n.IsScripted, scriptEventsJSON, n.IsEnd, n.EnemyBlueprint, n.EnemyCount, n.BlueprintID)
 		if err != nil {
 			return err
 		}
@@ -44,7 +44,7 @@ func (r *explorationRepository) CreateNodes(nodes []Node) error {
 }
 
 func (r *explorationRepository) GetNodesByExpeditionID(expeditionID uuid.UUID) ([]Node, error) {
-	query := `SELECT id, expedition_id, name, type, zone, hazard, environment_description, difficulty_multiplier, position_index, choices, is_resolved, terrain, detection_threshold, next_nodes, is_scripted, script_events, is_end, enemy_blueprint, enemy_count 
+	query := `SELECT id, expedition_id, name, type, zone, hazard, environment_description, difficulty_multiplier, position_index, choices, is_resolved, terrain, detection_threshold, next_nodes, is_scripted, script_events, is_end, enemy_blueprint, enemy_count, blueprint_id 
 	          FROM nodes WHERE expedition_id = $1 ORDER BY position_index ASC`
 	rows, err := r.db.Query(query, expeditionID)
 	if err != nil {
@@ -57,7 +57,7 @@ func (r *explorationRepository) GetNodesByExpeditionID(expeditionID uuid.UUID) (
 		var n Node
 		var choicesJSON []byte
 		var scriptEventsJSON []byte
-		if err := rows.Scan(&n.ID, &n.ExpeditionID, &n.Name, &n.Type, &n.Zone, &n.Hazard, &n.EnvironmentDescription, &n.DifficultyMultiplier, &n.PositionIndex, &choicesJSON, &n.IsResolved, &n.Terrain, &n.DetectionThreshold, pq.Array(&n.NextNodes), &n.IsScripted, &scriptEventsJSON, &n.IsEnd, &n.EnemyBlueprint, &n.EnemyCount); err != nil {
+		if err := rows.Scan(&n.ID, &n.ExpeditionID, &n.Name, &n.Type, &n.Zone, &n.Hazard, &n.EnvironmentDescription, &n.DifficultyMultiplier, &n.PositionIndex, &choicesJSON, &n.IsResolved, &n.Terrain, &n.DetectionThreshold, pq.Array(&n.NextNodes), &n.IsScripted, &scriptEventsJSON, &n.IsEnd, &n.EnemyBlueprint, &n.EnemyCount, &n.BlueprintID); err != nil {
 			return nil, err
 		}
 		if err := json.Unmarshal(choicesJSON, &n.Choices); err != nil {
@@ -75,12 +75,12 @@ func (r *explorationRepository) GetNodesByExpeditionID(expeditionID uuid.UUID) (
 }
 
 func (r *explorationRepository) GetNodeByID(id uuid.UUID) (*Node, error) {
-	query := `SELECT id, expedition_id, name, type, zone, hazard, environment_description, difficulty_multiplier, position_index, choices, is_resolved, terrain, detection_threshold, next_nodes, is_scripted, script_events, is_end, enemy_blueprint, enemy_count 
+	query := `SELECT id, expedition_id, name, type, zone, hazard, environment_description, difficulty_multiplier, position_index, choices, is_resolved, terrain, detection_threshold, next_nodes, is_scripted, script_events, is_end, enemy_blueprint, enemy_count, blueprint_id 
 	          FROM nodes WHERE id = $1`
 	var n Node
 	var choicesJSON []byte
 	var scriptEventsJSON []byte
-	err := r.db.QueryRow(query, id).Scan(&n.ID, &n.ExpeditionID, &n.Name, &n.Type, &n.Zone, &n.Hazard, &n.EnvironmentDescription, &n.DifficultyMultiplier, &n.PositionIndex, &choicesJSON, &n.IsResolved, &n.Terrain, &n.DetectionThreshold, pq.Array(&n.NextNodes), &n.IsScripted, &scriptEventsJSON, &n.IsEnd, &n.EnemyBlueprint, &n.EnemyCount)
+	err := r.db.QueryRow(query, id).Scan(&n.ID, &n.ExpeditionID, &n.Name, &n.Type, &n.Zone, &n.Hazard, &n.EnvironmentDescription, &n.DifficultyMultiplier, &n.PositionIndex, &choicesJSON, &n.IsResolved, &n.Terrain, &n.DetectionThreshold, pq.Array(&n.NextNodes), &n.IsScripted, &scriptEventsJSON, &n.IsEnd, &n.EnemyBlueprint, &n.EnemyCount, &n.BlueprintID)
 	if err != nil {
 		return nil, err
 	}
@@ -107,31 +107,56 @@ func (r *explorationRepository) UpdateNode(n *Node) error {
 }
 
 func (r *explorationRepository) CreateExpedition(e *Expedition) error {
-	query := `INSERT INTO expeditions (id, user_id, sub_sector_id, planet_location_id, vehicle_id, title, description, goal, status) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.Exec(query, e.ID, e.UserID, e.SubSectorID, e.PlanetLocationID, e.VehicleID, e.Title, e.Description, e.Goal, e.Status)
+	var siteJSON []byte
+	if e.SiteSnapshot != nil {
+		var err error
+		if siteJSON, err = json.Marshal(e.SiteSnapshot); err != nil {
+			return err
+		}
+	}
+	query := `INSERT INTO expeditions (id, user_id, sub_sector_id, planet_location_id, vehicle_id, title, description, goal, status, difficulty, seed, radar_level, timeline_length, alarm_level, site_snapshot) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	_, err := r.db.Exec(query, e.ID, e.UserID, e.SubSectorID, e.PlanetLocationID, e.VehicleID, e.Title, e.Description, e.Goal, e.Status, e.Difficulty, e.Seed, e.RadarLevel, e.TimelineLength, e.AlarmLevel, siteJSON)
+	return err
+}
+
+func (r *explorationRepository) UpdateExpeditionStatus(id uuid.UUID, status ExpeditionStatus) error {
+	query := `UPDATE expeditions SET status = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, status, id)
+	return err
+}
+
+func (r *explorationRepository) UpdateExpeditionAlarm(id uuid.UUID, level int) error {
+	query := `UPDATE expeditions SET alarm_level = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, level, id)
 	return err
 }
 
 func (r *explorationRepository) GetExpeditionByID(id uuid.UUID) (*Expedition, error) {
-	query := `SELECT id, user_id, sub_sector_id, planet_location_id, vehicle_id, title, description, goal, status FROM expeditions WHERE id = $1`
+	query := `SELECT id, user_id, sub_sector_id, planet_location_id, vehicle_id, title, description, goal, status, difficulty, seed, radar_level, timeline_length, alarm_level, site_snapshot FROM expeditions WHERE id = $1`
 	var e Expedition
-	err := r.db.QueryRow(query, id).Scan(&e.ID, &e.UserID, &e.SubSectorID, &e.PlanetLocationID, &e.VehicleID, &e.Title, &e.Description, &e.Goal, &e.Status)
+	var siteJSON []byte
+	err := r.db.QueryRow(query, id).Scan(&e.ID, &e.UserID, &e.SubSectorID, &e.PlanetLocationID, &e.VehicleID, &e.Title, &e.Description, &e.Goal, &e.Status, &e.Difficulty, &e.Seed, &e.RadarLevel, &e.TimelineLength, &e.AlarmLevel, &siteJSON)
 	if err != nil {
 		return nil, err
 	}
+	if len(siteJSON) > 0 {
+		if err := json.Unmarshal(siteJSON, &e.SiteSnapshot); err != nil {
+			return nil, err
+		}
+	}
 	return &e, nil
 }
 
 func (r *explorationRepository) SaveEncounter(e *Encounter, expeditionID uuid.UUID) error {
-	query := `INSERT INTO encounters (id, expedition_id, type, title, description, visual_prompt, enemy_id, terrain, detection_threshold) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.Exec(query, e.ID, expeditionID, e.Type, e.Title, e.Description, e.VisualPrompt, e.EnemyID, e.Terrain, e.DetectionThreshold)
+	query := `INSERT INTO encounters (id, expedition_id, node_id, type, title, description, visual_prompt, enemy_id, terrain, detection_threshold) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Exec(query, e.ID, expeditionID, e.NodeID, e.Type, e.Title, e.Description, e.VisualPrompt, e.EnemyID, e.Terrain, e.DetectionThreshold)
 	return err
 }
 
 func (r *explorationRepository) GetEncountersByExpeditionID(expeditionID uuid.UUID) ([]Encounter, error) {
-	query := `SELECT id, type, title, description, visual_prompt, enemy_id, terrain, detection_threshold, created_at FROM encounters WHERE expedition_id = $1 ORDER BY created_at ASC`
+	query := `SELECT id, expedition_id, node_id, type, title, description, visual_prompt, enemy_id, terrain, detection_threshold, created_at FROM encounters WHERE expedition_id = $1 ORDER BY created_at ASC`
 	rows, err := r.db.Query(query, expeditionID)
 	if err != nil {
 		return nil, err
@@ -141,7 +166,7 @@ func (r *explorationRepository) GetEncountersByExpeditionID(expeditionID uuid.UU
 	var encounters []Encounter
 	for rows.Next() {
 		var e Encounter
-		if err := rows.Scan(&e.ID, &e.Type, &e.Title, &e.Description, &e.VisualPrompt, &e.EnemyID, &e.Terrain, &e.DetectionThreshold, &e.CreatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.ExpeditionID, &e.NodeID, &e.Type, &e.Title, &e.Description, &e.VisualPrompt, &e.EnemyID, &e.Terrain, &e.DetectionThreshold, &e.CreatedAt); err != nil {
 			return nil, err
 		}
 		encounters = append(encounters, e)
@@ -149,6 +174,19 @@ func (r *explorationRepository) GetEncountersByExpeditionID(expeditionID uuid.UU
 	return encounters, nil
 }
 
+func (r *explorationRepository) GetEncounterByID(id uuid.UUID) (*Encounter, error) {
+	query := `SELECT id, expedition_id, node_id, type, title, description, visual_prompt, enemy_id, terrain, detection_threshold, created_at FROM encounters WHERE id = $1`
+	var e Encounter
+	err := r.db.QueryRow(query, id).Scan(&e.ID, &e.ExpeditionID, &e.NodeID, &e.Type, &e.Title, &e.Description, &e.VisualPrompt, &e.EnemyID, &e.Terrain, &e.DetectionThreshold, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
 func (r *explorationRepository) GetSessionByUserID(userID uuid.UUID) (*Session, error) {
 	query := `SELECT id, user_id, vehicle_id, current_node_id, status FROM exploration_sessions WHERE user_id = $1`
 	var s Session
@@ -219,6 +257,45 @@ func (r *explorationRepository) GetPlanetLocationsBySubSectorID(subSectorID uuid
 	return locations, nil
 }
 
+func (r *explorationRepository) GetSectorByID(id uuid.UUID) (*Sector, error) {
+	query := `SELECT id, name, description, difficulty, coordinates_x, coordinates_y, color FROM sectors WHERE id = $1`
+	var s Sector
+	err := r.db.QueryRow(query, id).Scan(&s.ID, &s.Name, &s.Description, &s.Difficulty, &s.CoordinatesX, &s.CoordinatesY, &s.Color)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *explorationRepository) GetSubSectorByID(id uuid.UUID) (*SubSector, error) {
+	query := `SELECT id, sector_id, type, name, description, rewards, requirements, allowed_modes, requires_atmosphere, terrain, detection_threshold, suitability_pilot, suitability_vehicle, coordinates_x, coordinates_y FROM sub_sectors WHERE id = $1`
+	var ss SubSector
+	err := r.db.QueryRow(query, id).Scan(&ss.ID, &ss.SectorID, &ss.Type, &ss.Name, &ss.Description, pq.Array(&ss.Rewards), pq.Array(&ss.Requirements), pq.Array(&ss.AllowedModes), &ss.RequiresAtmosphere, &ss.Terrain, &ss.DetectionThreshold, &ss.SuitabilityPilot, &ss.SuitabilityVehicle, &ss.CoordinatesX, &ss.CoordinatesY)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ss, nil
+}
+
+func (r *explorationRepository) GetPlanetLocationByID(id uuid.UUID) (*PlanetLocation, error) {
+	query := `SELECT id, sub_sector_id, name, description, rewards, requirements, allowed_modes, requires_atmosphere, terrain, detection_threshold, suitability_pilot, suitability_vehicle, coordinates_x, coordinates_y FROM planet_locations WHERE id = $1`
+	var pl PlanetLocation
+	err := r.db.QueryRow(query, id).Scan(&pl.ID, &pl.SubSectorID, &pl.Name, &pl.Description, pq.Array(&pl.Rewards), pq.Array(&pl.Requirements), pq.Array(&pl.AllowedModes), &pl.RequiresAtmosphere, &pl.Terrain, &pl.DetectionThreshold, &pl.SuitabilityPilot, &pl.SuitabilityVehicle, &pl.CoordinatesX, &pl.CoordinatesY)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pl, nil
+}
+
 func (r *explorationRepository) GetNodesByStarID(starID uuid.UUID) ([]Node, error) {
 	query := `SELECT id, name, type, environment_description, difficulty_multiplier, position_index, choices, is_resolved FROM nodes WHERE star_id = $1 ORDER BY position_index ASC`
 	rows, err := r.db.Query(query, starID)
@@ -253,3 +330,29 @@ func (r *explorationRepository) UpdateSession(s *Session) error {
 	_, err := r.db.Exec(query, s.CurrentNodeID, s.Status, s.ID)
 	return err
 }
+
+func (r *explorationRepository) CreateEnemyInstance(e *EnemyInstance) error {
+	query := `INSERT INTO enemy_instances (id, expedition_id, encounter_id, blueprint_id, name, scale, hp, max_hp, attack, defense, speed) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Exec(query, e.ID, e.ExpeditionID, e.EncounterID, e.BlueprintID, e.Name, e.Scale, e.HP, e.MaxHP, e.Attack, e.Defense, e.Speed)
+	return err
+}
+
+func (r *explorationRepository) GetEnemyInstanceByID(id uuid.UUID) (*EnemyInstance, error) {
+	query := `SELECT id, expedition_id, encounter_id, blueprint_id, name, scale, hp, max_hp, attack, defense, speed, created_at, updated_at FROM enemy_instances WHERE id = $1`
+	var e EnemyInstance
+	err := r.db.QueryRow(query, id).Scan(&e.ID, &e.ExpeditionID, &e.EncounterID, &e.BlueprintID, &e.Name, &e.Scale, &e.HP, &e.MaxHP, &e.Attack, &e.Defense, &e.Speed, &e.CreatedAt, &e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *explorationRepository) UpdateEnemyInstanceHP(id uuid.UUID, hp int) error {
+	query := `UPDATE enemy_instances SET hp = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := r.db.Exec(query, hp, id)
+	return err
+}
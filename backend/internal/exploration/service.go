@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/combat"
 	"github.com/ryudokung/Project-0/backend/internal/game"
 	"github.com/ryudokung/Project-0/backend/internal/vehicle"
 )
@@ -74,6 +76,8 @@ type StrategicChoice struct {
 	SuccessChance float64 `json:"success_chance"`
 	Rewards      []string `json:"rewards"`
 	Risks        []string `json:"risks"`
+	LootTable    string   `json:"loot_table,omitempty"` // Rolled on success; Rewards is then only a label
+	Approach     ApproachType `json:"approach,omitempty"` // STEALTH and LOUD move the expedition's alarm
 }
 
 type Node struct {
@@ -109,6 +113,8 @@ type Session struct {
 
 type Repository interface {
 	CreateExpedition(expedition *Expedition) error
+	UpdateExpeditionStatus(id uuid.UUID, status ExpeditionStatus) error
+	UpdateExpeditionAlarm(id uuid.UUID, level int) error
 	GetExpeditionByID(id uuid.UUID) (*Expedition, error)
 	
 	// Timeline Nodes
@@ -120,10 +126,19 @@ type Repository interface {
 	// Legacy/Other
 	SaveEncounter(encounter *Encounter, expeditionID uuid.UUID) error
 	GetEncountersByExpeditionID(expeditionID uuid.UUID) ([]Encounter, error)
+	GetEncounterByID(id uuid.UUID) (*Encounter, error)
 	GetSessionByUserID(userID uuid.UUID) (*Session, error)
 	GetAllSectors() ([]Sector, error)
 	GetSubSectorsBySectorID(sectorID uuid.UUID) ([]SubSector, error)
 	GetPlanetLocationsBySubSectorID(subSectorID uuid.UUID) ([]PlanetLocation, error)
+	GetSectorByID(id uuid.UUID) (*Sector, error)
+	GetSubSectorByID(id uuid.UUID) (*SubSector, error)
+	GetPlanetLocationByID(id uuid.UUID) (*PlanetLocation, error)
+
+	// Enemy Instances
+	CreateEnemyInstance(enemy *EnemyInstance) error
+	GetEnemyInstanceByID(id uuid.UUID) (*EnemyInstance, error)
+	UpdateEnemyInstanceHP(id uuid.UUID, hp int) error
 }
 
 type Sector struct {
@@ -172,19 +187,27 @@ type PlanetLocation struct {
 }
 
 type Expedition struct {
-	ID               uuid.UUID  `json:"id"`
-	UserID           uuid.UUID  `json:"user_id"`
-	SubSectorID      *uuid.UUID `json:"sub_sector_id"`
-	PlanetLocationID *uuid.UUID `json:"planet_location_id"`
-	VehicleID        *uuid.UUID `json:"vehicle_id"`
-	Title            string     `json:"title"`
-	Description      string     `json:"description"`
-	Goal             string     `json:"goal"`
-	Status           string     `json:"status"`
+	ID               uuid.UUID        `json:"id"`
+	UserID           uuid.UUID        `json:"user_id"`
+	SubSectorID      *uuid.UUID       `json:"sub_sector_id"`
+	PlanetLocationID *uuid.UUID       `json:"planet_location_id"`
+	VehicleID        *uuid.UUID       `json:"vehicle_id"`
+	Title            string           `json:"title"`
+	Description      string           `json:"description"`
+	Goal             string           `json:"goal"`
+	Status           ExpeditionStatus `json:"status"`
+	Difficulty       int              `json:"difficulty"`      // 1 = normal; scales spawned enemies
+	Seed             int64            `json:"seed"`            // Procedural timeline seed; 0 for handcrafted expeditions
+	RadarLevel       int              `json:"radar_level"`     // Radar level the timeline was generated with
+	TimelineLength   int              `json:"timeline_length"` // Procedural node count
+	AlarmLevel       int              `json:"alarm_level"`     // 0-100 sector awareness; see AlarmTierFor
+	SiteSnapshot     *Site            `json:"site_snapshot,omitempty"` // Site as generated from, so regeneration ignores later map edits
 }
 
 type Encounter struct {
 	ID                 uuid.UUID   `json:"id"`
+	ExpeditionID       uuid.UUID   `json:"expedition_id"`
+	NodeID             *uuid.UUID  `json:"node_id,omitempty"`
 	Type               NodeType    `json:"type"`
 	Title              string      `json:"title"`
 	Description        string      `json:"description"`
@@ -214,6 +237,7 @@ func NewService(repo Repository, vehicleUseCase vehicle.UseCase, gameRepo game.R
 func (s *Service) StartExploration(ctx context.Context, userID uuid.UUID, subSectorID uuid.UUID, planetLocationID *uuid.UUID, vehicleID uuid.UUID) (*Expedition, error) {
 	// 0. Verify Vehicle Ownership (if vehicle is provided)
 	var vID *uuid.UUID
+	modes := []string{ModePilot}
 	if vehicleID != uuid.Nil {
 		// Check items table first (Unified System)
 		item, err := s.vehicleUseCase.GetItemByID(ctx, vehicleID)
@@ -233,10 +257,16 @@ func (s *Service) StartExploration(ctx context.Context, userID uuid.UUID, subSec
 			if v.OwnerID != userID {
 				return nil, fmt.Errorf("unauthorized: you do not own this vehicle")
 			}
+			modes = []string{string(v.VehicleType)}
 		} else {
 			if item.OwnerID != userID {
 				return nil, fmt.Errorf("unauthorized: you do not own this vehicle")
 			}
+			// Unified items carry no vehicle type; use the legacy record's when there is one
+			modes = []string{ModeVehicle}
+			if v, _ := s.vehicleUseCase.GetVehicleByID(ctx, vehicleID); v != nil {
+				modes = []string{string(v.VehicleType)}
+			}
 		}
 		vID = &vehicleID
 	}
@@ -246,24 +276,7 @@ func (s *Service) StartExploration(ctx context.Context, userID uuid.UUID, subSec
 		ssID = &subSectorID
 	}
 
-	// 1. Create Expedition
-	expedition := &Expedition{
-		ID:               uuid.New(),
-		UserID:           userID,
-		SubSectorID:      ssID,
-		PlanetLocationID: planetLocationID,
-		VehicleID:        vID,
-		Title:            "The Silent Signal",
-		Description:      "Investigating a mysterious signal in the sector.",
-		Goal:             "Locate the source of the signal.",
-		Status:           "ACTIVE",
-	}
-
-	if err := s.repo.CreateExpedition(expedition); err != nil {
-		return nil, err
-	}
-
-	// 1.5 Ensure Pilot has resources to start
+	// 1. Ensure Pilot has resources to start
 	pilot, _ := s.gameRepo.GetActivePilotStats(userID)
 	if pilot != nil && (pilot.CurrentO2 < 20 || pilot.CurrentFuel < 10) {
 		pilot.CurrentO2 = 100.0
@@ -271,20 +284,60 @@ func (s *Service) StartExploration(ctx context.Context, userID uuid.UUID, subSec
 		_ = s.gameRepo.UpdatePilotStats(pilot)
 	}
 
-	// 2. Generate Timeline (5-7 nodes)
 	radarLevel := 1
 	if pilot != nil && pilot.Metadata != nil {
 		if lv, ok := pilot.Metadata["radar_level"].(float64); ok {
 			radarLevel = int(lv)
 		}
 	}
-	nodes := s.GenerateTimeline(expedition.ID, 6, radarLevel)
+
+	site, err := s.LoadSite(ssID, planetLocationID)
+	if err != nil {
+		return nil, err
+	}
+	if site != nil {
+		if vID == nil && pilot != nil && pilot.EquippedExosuitID != nil {
+			modes = append(modes, ModeExosuit)
+		}
+		if err := site.CheckAccess(pilot, modes...); err != nil {
+			return nil, err
+		}
+	}
+	title, description, goal := "The Silent Signal", "Investigating a mysterious signal in the sector.", "Locate the source of the signal."
+	if site != nil {
+		title, description, goal = site.Briefing()
+	}
+
+	// 2. Create Expedition with everything its timeline is generated from
+	expedition := &Expedition{
+		ID:               uuid.New(),
+		UserID:           userID,
+		SubSectorID:      ssID,
+		PlanetLocationID: planetLocationID,
+		VehicleID:        vID,
+		Title:            title,
+		Description:      description,
+		Goal:             goal,
+		Status:           ExpeditionActive,
+		Difficulty:       site.ExpeditionDifficulty(),
+		Seed:             rand.Int63(),
+		RadarLevel:       radarLevel,
+		TimelineLength:   proceduralTimelineLength,
+		SiteSnapshot:     site,
+	}
+
+	if err := s.repo.CreateExpedition(expedition); err != nil {
+		return nil, err
+	}
+
+	// 3. Generate Timeline
+	nodes := s.GenerateSeededTimeline(expedition.ID, expedition.TimelineParams(), site)
 	if err := s.repo.CreateNodes(nodes); err != nil {
 		return nil, err
 	}
 
-	// 3. Generate First Encounter
-	_, err := s.GenerateNewEncounter(ctx, expedition.ID, vehicleID)
+	// 4. Generate First Encounter
+	_, err = s.GenerateNewEncounter(ctx, expedition.ID, vehicleID, "")
 	if err != nil {
 		// Log error but don't fail start (we can generate it later if needed)
 		fmt.Printf("Warning: failed to generate first encounter: %v\n", err)
@@ -313,7 +366,8 @@ func (s *Service) CreateHandcraftedExpedition(ctx context.Context, userID uuid.U
 		Title:       blueprint.Title,
 		Description: blueprint.Description,
 		Goal:        "Complete the mission objectives.",
-		Status:      "ACTIVE",
+		Status:      ExpeditionActive,
+		Difficulty:  blueprint.Difficulty,
 	}
 
 	if err := s.repo.CreateExpedition(expedition); err != nil {
@@ -359,7 +413,7 @@ func (s *Service) CreateHandcraftedExpedition(ctx context.Context, userID uuid.U
 		genVID = *vID
 	}
 	
-	_, err := s.GenerateNewEncounter(ctx, expedition.ID, genVID)
+	_, err := s.GenerateNewEncounter(ctx, expedition.ID, genVID, "")
 	if err != nil {
 		// Log error but don't fail start (we can generate it later if needed)
 		fmt.Printf("Warning: failed to generate first encounter for handcrafted mission: %v\n", err)
@@ -368,14 +422,31 @@ func (s *Service) CreateHandcraftedExpedition(ctx context.Context, userID uuid.U
 	return expedition, nil
 }
 
+// GenerateTimeline builds a procedural timeline from a fresh random seed
 func (s *Service) GenerateTimeline(expeditionID uuid.UUID, length int, radarLevel int) []Node {
+	return s.GenerateSeededTimeline(expeditionID, TimelineParams{Seed: rand.Int63(), Length: length, RadarLevel: radarLevel}, nil)
+}
+
+// GenerateSeededTimeline builds a procedural timeline as a pure function of params, the site (nil for
+// unmapped space) and the loaded node blueprints: the same inputs always produce the same nodes, IDs included.
+func (s *Service) GenerateSeededTimeline(expeditionID uuid.UUID, params TimelineParams, site *Site) []Node {
+	length := params.Length
+	radarLevel := params.RadarLevel
+	rng := rand.New(rand.NewSource(params.Seed))
 	nodes := make([]Node, length)
-	types := []NodeType{NodeStandard, NodeResource, NodeCombat, NodeAnomaly, NodeNarrative}
+	types := site.nodePool()
 	terrains := []TerrainType{TerrainIndustrial, TerrainMining, TerrainCyber, TerrainAncient}
-	hazards := []HazardType{HazardNone, HazardEMPStorm, HazardCorrosiveRain, HazardSolarFlare, HazardVoidEcho}
+	hazards := s.hazardPool(site)
+	baseMultiplier, _, hazardChance := site.difficulty()
+
+	// Vacuum sites are mostly walked in suits or through hull corridors
+	evaChance, corridorChance := 0.2, 0.2
+	if site != nil && !site.RequiresAtmosphere() {
+		evaChance, corridorChance = 0.35, 0.35
+	}
 
 	for i := 0; i < length; i++ {
-		nodeType := types[rand.Intn(len(types))]
+		nodeType := types[rng.Intn(len(types))]
 		zone := ZoneSurface
 		
 		// Logic for Zone distribution
@@ -387,30 +458,25 @@ func (s *Service) GenerateTimeline(expeditionID uuid.UUID, length int, radarLeve
 			nodeType = NodeOutpost // End with Outpost
 		} else {
 			// Randomly assign EVA or Corridor for variety
-			r := rand.Float64()
-			if r < 0.2 {
+			r := rng.Float64()
+			if r < evaChance {
 				zone = ZoneEVA
-			} else if r < 0.4 {
+			} else if r < evaChance+corridorChance {
 				zone = ZoneCorridor
 			}
 		}
 
-		terrain := terrains[rand.Intn(len(terrains))]
+		terrain := terrains[rng.Intn(len(terrains))]
+		if site != nil {
+			terrain = site.Terrain()
+		}
 		hazard := HazardNone
-		if rand.Float64() < 0.3 { // 30% chance of hazard
-			hazard = hazards[1+rand.Intn(len(hazards)-1)]
+		if len(hazards) > 0 && rng.Float64() < hazardChance {
+			hazard = hazards[rng.Intn(len(hazards))]
 		}
 
 		// Find matching blueprint
-		var blueprint game.NodeBlueprint
-		found := false
-		for _, b := range s.blueprints.Nodes {
-			if b.Type == string(nodeType) {
-				blueprint = b
-				found = true
-				break
-			}
-		}
+		blueprint, found := s.pickNodeBlueprint(nodeType, rng)
 
 		if !found {
 			// Fallback
@@ -428,6 +494,7 @@ func (s *Service) GenerateTimeline(expeditionID uuid.UUID, length int, radarLeve
 		if blueprint.Zone != "" {
 			zone = ZoneType(blueprint.Zone)
 		}
+		zone = site.fitZone(zone)
 
 		// Map blueprint choices to StrategicChoice
 		choices := make([]StrategicChoice, len(blueprint.Choices))
@@ -439,15 +506,21 @@ func (s *Service) GenerateTimeline(expeditionID uuid.UUID, length int, radarLeve
 				Rewards:       c.Rewards,
 				Risks:         c.Risks,
 				Requirements:  c.Requirements,
+				LootTable:     choiceLootTable(blueprint, c),
+				Approach:      ApproachType(c.Approach),
 			}
 		}
 
 		// Radar reduces the detection threshold (making it easier to navigate stealthily)
-		baseThreshold := 500 + rand.Intn(500)
+		baseThreshold := 500 + rng.Intn(500)
+		if site != nil && site.DetectionThreshold() > 0 {
+			// +/-25% around the site's own threshold
+			baseThreshold = int(float64(site.DetectionThreshold()) * (0.75 + rng.Float64()*0.5))
+		}
 		detectionThreshold := int(float64(baseThreshold) / (1.0 + float64(radarLevel-1)*0.2))
 
 		nodes[i] = Node{
-			ID:                     uuid.New(),
+			ID:                     timelineNodeID(expeditionID, i),
 			ExpeditionID:           expeditionID,
 			BlueprintID:            blueprint.ID,
 			Name:                   blueprint.Name,
@@ -455,7 +528,7 @@ func (s *Service) GenerateTimeline(expeditionID uuid.UUID, length int, radarLeve
 			Zone:                   zone,
 			Hazard:                 hazard,
 			EnvironmentDescription: blueprint.Description,
-			DifficultyMultiplier:   1.0 + (float64(i) * 0.1),
+			DifficultyMultiplier:   baseMultiplier * (1.0 + (float64(i) * 0.1)),
 			PositionIndex:          i,
 			Choices:                choices,
 			IsResolved:             false,
@@ -470,7 +543,8 @@ func (s *Service) GenerateTimeline(expeditionID uuid.UUID, length int, radarLeve
 // ECP = (Base_CP * Suitability_Mod) * Resonance_Sync * (1 - Fatigue_Penalty)
 // CalculateEffectiveCP implements the blueprint formula:
 // ECP = (Vehicle_CP + Exosuit_CP) * Suitability_Mod * Resonance_Sync * (1 - Fatigue_Penalty) * Synergy_Mod
-func (s *Service) CalculateEffectiveCP(ctx context.Context, userID uuid.UUID, vehicleID uuid.UUID, terrain TerrainType) (int, error) {
+// site may be nil; otherwise its suitability score for the deployment scales the result.
+func (s *Service) CalculateEffectiveCP(ctx context.Context, userID uuid.UUID, vehicleID uuid.UUID, terrain TerrainType, site *Site) (int, error) {
 	// 1. Get Pilot Stats
 	pilot, err := s.gameRepo.GetActivePilotStats(userID)
 	if err != nil {
@@ -490,7 +564,7 @@ func (s *Service) CalculateEffectiveCP(ctx context.Context, userID uuid.UUID, ve
 	// 2. Handle Pilot Only Mode
 	if vehicleID == uuid.Nil {
 		// Base Pilot CP is 50
-		// Suitability is always 1.0 for pilot
+		// Suitability comes from the site's pilot score (see Site.Suitability)
 		// Resonance Sync is always 1.0 for pilot
 		fatiguePenalty := float64(pilot.Stress) / 200.0
 		if fatiguePenalty > 0.5 {
@@ -507,7 +581,7 @@ func (s *Service) CalculateEffectiveCP(ctx context.Context, userID uuid.UUID, ve
 			}
 		}
 
-		ecp := 50.0 * (1.0 - fatiguePenalty)
+		ecp := 50.0 * site.Suitability(true) * (1.0 - fatiguePenalty)
 		return int(ecp), nil
 	}
 
@@ -574,6 +648,8 @@ func (s *Service) CalculateEffectiveCP(ctx context.Context, userID uuid.UUID, ve
 		}
 	}
 
+	suitabilityMod *= site.Suitability(false)
+
 	// 7. Calculate Resonance Sync
 	// Formula: Min(1.0, Pilot_Resonance / Vehicle_Tier_Requirement)
 	tierReq := v.Tier * 20
@@ -639,10 +715,23 @@ func (s *Service) ResolveNode(ctx context.Context, nodeID uuid.UUID) (*Node, err
 	if err != nil {
 		return nil, err
 	}
+	if node.IsResolved {
+		return nil, ErrNodeAlreadyResolved
+	}
+	expedition, err := s.repo.GetExpeditionByID(node.ExpeditionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanResolve(expedition); err != nil {
+		return nil, err
+	}
 	node.IsResolved = true
 	if err := s.repo.UpdateNode(node); err != nil {
 		return nil, err
 	}
+	if err := s.completeAfter(ctx, expedition, node); err != nil {
+		return nil, err
+	}
 	return node, nil
 }
 
@@ -653,7 +742,7 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 		return nil, err
 	}
 	if node.IsResolved {
-		return nil, fmt.Errorf("node already resolved")
+		return nil, ErrNodeAlreadyResolved
 	}
 
 	// 2. Find Choice
@@ -673,6 +762,9 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 	if err != nil {
 		return nil, err
 	}
+	if err := checkCanResolve(expedition); err != nil {
+		return nil, err
+	}
 
 	vehicleID := uuid.Nil
 	if expedition.VehicleID != nil {
@@ -693,6 +785,11 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 		fuelCost = blueprint.ResourceCosts.Fuel
 		o2Cost = blueprint.ResourceCosts.O2
 	}
+	hazard := s.HazardEffect(ctx, node.Hazard, expedition, stats)
+	if hazard != nil {
+		fuelCost *= hazard.FuelDrain
+		o2Cost *= hazard.O2Drain
+	}
 
 	if stats.CurrentFuel < fuelCost || stats.CurrentO2 < o2Cost {
 		// Trigger Emergency Retrieval
@@ -703,10 +800,11 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 		stats.Metadata["emergency_retrieval"] = true
 		_ = s.gameRepo.UpdatePilotStats(stats)
 		
-		// Mark expedition as failed/ended
-		expedition.Status = "FAILED"
-		// In a real system, we'd update the expedition status in DB
-		
+		// The pilot is pulled out: the expedition ends here
+		if err := s.TransitionExpedition(ctx, expedition, ExpeditionFailed); err != nil {
+			return nil, err
+		}
+
 		return nil, fmt.Errorf("EMERGENCY RETRIEVAL: Insufficient resources (Fuel: %.1f/%.1f, O2: %.1f/%.1f)", 
 			stats.CurrentFuel, fuelCost, stats.CurrentO2, o2Cost)
 	}
@@ -766,7 +864,11 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 	}
 
 	// 4. Calculate Effective CP (ECP)
-	ecp, err := s.CalculateEffectiveCP(ctx, expedition.UserID, vehicleID, node.Terrain)
+	site, err := s.LoadSite(expedition.SubSectorID, expedition.PlanetLocationID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load expedition site: %v\n", err)
+	}
+	ecp, err := s.CalculateEffectiveCP(ctx, expedition.UserID, vehicleID, node.Terrain, site)
 	if err != nil {
 		// Fallback if calculation fails
 		ecp = 100
@@ -775,12 +877,16 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 	// Success Chance Adjustment based on ECP vs Node Difficulty
 	// Base difficulty is 200 * DifficultyMultiplier
 	baseDifficulty := 200.0 * node.DifficultyMultiplier
+	baseDifficulty *= alarmDifficulty(expedition.AlarmLevel) // A watchful sector is harder everywhere
 
 	// Detection Logic (Alarm Mode)
 	isAlarmMode := false
 	if vehicleID != uuid.Nil {
 		// Calculate Signature (Base on CP for now)
 		signature := float64(ecp)
+		if selectedChoice.Approach == ApproachStealth {
+			signature *= stealthSignatureMod
+		}
 		
 		// Bastion Radar reduces effective signature
 		radarLevel := 1.0
@@ -795,17 +901,17 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 		
 		effectiveSignature := signature / (1.0 + (radarLevel-1)*0.2)
 		
-		if int(effectiveSignature) > node.DetectionThreshold {
+		threshold := alarmThreshold(node.DetectionThreshold, expedition.AlarmLevel)
+		if int(effectiveSignature) > threshold {
 			isAlarmMode = true
 			// Alarm Mode increases difficulty significantly
 			baseDifficulty *= 2.0
-			fmt.Printf("ALARM MODE TRIGGERED: Signature %v > Threshold %v\n", int(effectiveSignature), node.DetectionThreshold)
+			fmt.Printf("ALARM MODE TRIGGERED: Signature %v > Threshold %v\n", int(effectiveSignature), threshold)
 		}
 	}
 
-	if isAlarmMode {
-		// Could add more alarm-specific logic here
-	}
+	// The alarm carries over to the rest of the expedition; it is saved with the node's resolution
+	alarmLevel := NextAlarmLevel(expedition.AlarmLevel, selectedChoice.Approach, isAlarmMode)
 
 	// Success chance formula: 0.5 + (ECP - Difficulty) / 1000
 	ecpBonus := (float64(ecp) - baseDifficulty) / 1000.0
@@ -817,6 +923,9 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 	}
 
 	finalSuccessChance := selectedChoice.SuccessChance + ecpBonus + requirementPenalty
+	if hazard != nil {
+		finalSuccessChance += hazard.SuccessChance
+	}
 	if finalSuccessChance > 0.98 {
 		finalSuccessChance = 0.98
 	}
@@ -827,6 +936,7 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 	success := rand.Float64() < finalSuccessChance
 
 	// 5. Apply Consequences
+	isEmergency := false
 	stats, err = s.gameRepo.GetActivePilotStats(expedition.UserID)
 	if err == nil && stats != nil {
 		// Get Bastion Module Levels (Migrated to Table)
@@ -851,19 +961,11 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 		stats.Stress += 5 + rand.Intn(5) // 5-10 stress per node
 		
 		// Apply Hazard Effects
-		switch node.Hazard {
-		case HazardVoidEcho:
-			stats.Stress += 10 // Extra stress from Void Echoes
-		case HazardSolarFlare:
-			stats.Stress += 5
-			// Could add Heat mechanic here
-		case HazardCorrosiveRain:
-			if expedition.VehicleID != nil {
-				// Apply durability damage to vehicle
-				_, _ = s.vehicleUseCase.ApplyDamage(ctx, *expedition.VehicleID, 5)
+		if hazard != nil {
+			stats.Stress += hazard.Stress
+			if hazard.DurabilityDamage > 0 && expedition.VehicleID != nil {
+				_, _ = s.vehicleUseCase.ApplyDamage(ctx, *expedition.VehicleID, hazard.DurabilityDamage)
 			}
-		case HazardEMPStorm:
-			// EMP reduces success chance or affects energy weapons in combat
 		}
 
 		if stats.Stress > 100 {
@@ -896,7 +998,6 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 		}
 
 		// Emergency Retrieval Protocol (Phase 2: Tactical Engine)
-		isEmergency := false
 		if stats.CurrentFuel <= 0 || stats.CurrentO2 <= 0 {
 			isEmergency = true
 			stats.Stress = 100
@@ -916,14 +1017,29 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 				xpMod *= 0.5
 			}
 
-			for _, reward := range selectedChoice.Rewards {
-				switch reward {
-				case "Scrap Metal":
-					stats.ScrapMetal += int(50.0 * rewardMod)
-				case "Research Data":
-					stats.ResearchData += int(20.0 * rewardMod)
-				case "Rare Ore":
-					stats.ScrapMetal += int(150.0 * rewardMod)
+			if selectedChoice.LootTable != "" {
+				// Same node and choice always roll the same loot
+				drop, err := s.blueprints.RollLoot(selectedChoice.LootTable, game.LootSeed(node.ID.String(), selectedChoice.Label), 1)
+				if err != nil {
+					return nil, err
+				}
+				drop.ScaleCurrencies(rewardMod)
+				drop.ApplyCurrencies(stats)
+				for _, li := range drop.Items {
+					if err := s.vehicleUseCase.CreateItem(ctx, li.NewItem(expedition.UserID, &stats.CharacterID)); err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				for _, reward := range selectedChoice.Rewards {
+					switch reward {
+					case "Scrap Metal":
+						stats.ScrapMetal += int(50.0 * rewardMod)
+					case "Research Data":
+						stats.ResearchData += int(20.0 * rewardMod)
+					case "Rare Ore":
+						stats.ScrapMetal += int(150.0 * rewardMod)
+					}
 				}
 			}
 			// Always give some XP on success
@@ -957,6 +1073,21 @@ func (s *Service) ResolveNodeChoice(ctx context.Context, nodeID uuid.UUID, choic
 	if err := s.repo.UpdateNode(node); err != nil {
 		return nil, err
 	}
+	if alarmLevel != expedition.AlarmLevel {
+		if err := s.repo.UpdateExpeditionAlarm(expedition.ID, alarmLevel); err != nil {
+			return nil, err
+		}
+		expedition.AlarmLevel = alarmLevel
+	}
+
+	// Resources ran out during the choice: Emergency Retrieval ends the expedition
+	if isEmergency {
+		if err := s.TransitionExpedition(ctx, expedition, ExpeditionFailed); err != nil {
+			return nil, err
+		}
+	} else if err := s.completeAfter(ctx, expedition, node); err != nil {
+		return nil, err
+	}
 
 	return node, nil
 }
@@ -988,18 +1119,32 @@ func (s *Service) GenerateChoicesForType(t NodeType) []StrategicChoice {
 			Rewards:       c.Rewards,
 			Risks:         c.Risks,
 			Requirements:  c.Requirements,
+			LootTable:     choiceLootTable(blueprint, c),
+			Approach:      ApproachType(c.Approach),
 		}
 	}
 	return choices
 }
 
-// GenerateNewEncounter generates a new procedural event (Encounter) based on the current Expedition context
-func (s *Service) GenerateNewEncounter(ctx context.Context, expeditionID uuid.UUID, vehicleID uuid.UUID) (*Encounter, error) {
+// choiceLootTable falls back to the node's loot table when the choice has none
+func choiceLootTable(node game.NodeBlueprint, c game.ChoiceBlueprint) string {
+	if c.LootTable != "" {
+		return c.LootTable
+	}
+	return node.LootTable
+}
+
+// GenerateNewEncounter generates a new procedural event (Encounter) based on the current Expedition context.
+// nextNodeID picks the branch to follow on a handcrafted expedition (a blueprint node ID; empty when there is only one way forward).
+func (s *Service) GenerateNewEncounter(ctx context.Context, expeditionID uuid.UUID, vehicleID uuid.UUID, nextNodeID string) (*Encounter, error) {
 	// 1. Fetch Context Data
 	expedition, err := s.repo.GetExpeditionByID(expeditionID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkCanAdvance(expedition); err != nil {
+		return nil, err
+	}
 
 	// Get all nodes for this expedition
 	nodes, err := s.repo.GetNodesByExpeditionID(expeditionID)
@@ -1011,24 +1156,39 @@ func (s *Service) GenerateNewEncounter(ctx context.Context, expeditionID uuid.UU
 	existingEncounters, _ := s.repo.GetEncountersByExpeditionID(expeditionID)
 	currentIndex := len(existingEncounters)
 
-	if currentIndex >= len(nodes) {
-		return nil, fmt.Errorf("expedition completed")
-	}
-
-	targetNode := nodes[currentIndex]
-
 	// Anti-Cheat: Check if last encounter is resolved
 	if currentIndex > 0 {
 		last := existingEncounters[currentIndex-1]
-		if last.Type == NodeCombat && last.EnemyID != nil {
-			// Check enemy status (using Item system if possible)
-			enemy, _ := s.vehicleUseCase.GetItemByID(ctx, *last.EnemyID)
-			if enemy != nil && enemy.Durability > 0 {
-				return nil, fmt.Errorf("current combat encounter not resolved")
+		if (last.Type == NodeCombat || last.Type == NodeBoss) && last.EnemyID != nil {
+			// The spawned enemy must be dead before moving on
+			enemy, err := s.repo.GetEnemyInstanceByID(*last.EnemyID)
+			if err != nil {
+				return nil, err
+			}
+			if enemy != nil && !enemy.IsDefeated() {
+				return nil, ErrCombatUnresolved
+			}
+		} else if last.NodeID != nil {
+			// Every other node needs one of its choices taken first
+			if node := nodeByID(nodes, *last.NodeID); node != nil && !node.IsResolved {
+				return nil, ErrNodeUnresolved
 			}
 		}
 	}
 
+	target, err := selectNextNode(nodes, existingEncounters, nextNodeID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		// Every node has been visited: head home
+		if err := s.TransitionExpedition(ctx, expedition, ExpeditionExtracting); err != nil {
+			return nil, err
+		}
+		return nil, ErrExpeditionExtracting
+	}
+	targetNode := *target
+
 	// Fetch Vehicle (Unified Item System)
 	var item *vehicle.Item
 	if vehicleID != uuid.Nil {
@@ -1069,8 +1229,11 @@ func (s *Service) GenerateNewEncounter(ctx context.Context, expeditionID uuid.UU
 		return nil, fmt.Errorf("insufficient oxygen to advance")
 	}
 
-	// 2. Determine Encounter Type from Timeline Node
+	// 2. Determine Encounter Type from Timeline Node; a raised alarm turns quiet nodes into fights
 	encounterType := targetNode.Type
+	if escalatesToCombat(&targetNode, nodes, expedition.AlarmLevel) {
+		encounterType = NodeCombat
+	}
 
 	// Consume resources (only if advancing, not for the first encounter)
 	// Skip for system expeditions or if pilot stats are missing
@@ -1109,36 +1272,51 @@ func (s *Service) GenerateNewEncounter(ctx context.Context, expeditionID uuid.UU
 	// 4. Generate Visual Prompt (DDS Integrated)
 	prompt := s.GenerateVisualPrompt(item, &targetNode)
 
-	var enemyID *uuid.UUID
+	encounterID := uuid.New()
+	var enemy *EnemyInstance
 	if encounterType == NodeCombat || encounterType == NodeBoss {
+		var blueprint *game.EnemyBlueprint
 		if targetNode.EnemyBlueprint != "" {
 			// Find enemy by name in blueprints
 			for id, b := range s.blueprints.Enemies {
 				if b.Name == targetNode.EnemyBlueprint || id == targetNode.EnemyBlueprint {
-					uid := uuid.MustParse(id)
-					enemyID = &uid
+					b := b
+					blueprint = &b
 					break
 				}
 			}
 		}
 
 		// Fallback to random enemy if not found or not specified
-		if enemyID == nil {
+		if blueprint == nil {
 			var enemyIDs []string
 			for id := range s.blueprints.Enemies {
 				enemyIDs = append(enemyIDs, id)
 			}
-			
+			sort.Strings(enemyIDs)
+
 			if len(enemyIDs) > 0 {
-				selected := enemyIDs[rand.Intn(len(enemyIDs))]
-				uid := uuid.MustParse(selected)
-				enemyID = &uid
+				b := s.blueprints.Enemies[enemyIDs[rand.Intn(len(enemyIDs))]]
+				blueprint = &b
 			}
 		}
+
+		// Spawn a concrete enemy for this encounter, scaled to the node and expedition
+		if blueprint != nil {
+			hazard := s.pilotHazardEffect(ctx, targetNode.Hazard, expedition)
+			enemy = NewEnemyInstance(*blueprint, expeditionID, encounterID, EnemyScale(&targetNode, expedition.Difficulty, hazard))
+		}
+	}
+
+	var enemyID *uuid.UUID
+	if enemy != nil {
+		enemyID = &enemy.ID
 	}
 
 	encounter := &Encounter{
-		ID:                 uuid.New(),
+		ID:                 encounterID,
+		ExpeditionID:       expeditionID,
+		NodeID:             &targetNode.ID,
 		Type:               encounterType,
 		Title:              title,
 		Description:        desc,
@@ -1153,10 +1331,151 @@ func (s *Service) GenerateNewEncounter(ctx context.Context, expeditionID uuid.UU
 	if err := s.repo.SaveEncounter(encounter, expeditionID); err != nil {
 		return nil, err
 	}
+	if enemy != nil {
+		if err := s.repo.CreateEnemyInstance(enemy); err != nil {
+			return nil, err
+		}
+	}
+
+	// A spawned enemy locks the expedition into combat; otherwise the node is cleared on arrival
+	if enemy != nil {
+		err = s.TransitionExpedition(ctx, expedition, ExpeditionInCombat)
+	} else {
+		err = s.advancePast(ctx, expedition, &targetNode, nodes)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return encounter, nil
 }
 
+// GetEncounterForCombat resolves a COMBAT/BOSS encounter into the enemy and script the combat module needs
+func (s *Service) GetEncounterForCombat(ctx context.Context, encounterID uuid.UUID) (*combat.EncounterInfo, error) {
+	encounter, err := s.repo.GetEncounterByID(encounterID)
+	if err != nil {
+		return nil, err
+	}
+	if encounter == nil {
+		return nil, fmt.Errorf("encounter not found")
+	}
+	if (encounter.Type != NodeCombat && encounter.Type != NodeBoss) || encounter.EnemyID == nil {
+		return nil, fmt.Errorf("encounter has no enemy to fight")
+	}
+
+	expedition, err := s.repo.GetExpeditionByID(encounter.ExpeditionID)
+	if err != nil {
+		return nil, err
+	}
+	if expedition.Status.IsFinished() {
+		return nil, ErrExpeditionFinished
+	}
+
+	info := &combat.EncounterInfo{
+		EncounterID:  encounter.ID,
+		ExpeditionID: expedition.ID,
+		UserID:       expedition.UserID,
+		VehicleID:    expedition.VehicleID,
+		EnemyID:      *encounter.EnemyID,
+	}
+
+	instance, err := s.repo.GetEnemyInstanceByID(*encounter.EnemyID)
+	if err != nil {
+		return nil, err
+	}
+	if instance != nil {
+		if instance.IsDefeated() {
+			return nil, fmt.Errorf("enemy already defeated")
+		}
+		bp, ok := s.blueprints.Enemies[instance.BlueprintID]
+		if !ok {
+			return nil, fmt.Errorf("enemy blueprint %s not found", instance.BlueprintID)
+		}
+		info.Enemy = instance.Blueprint(bp)
+		info.EnemyHP = instance.HP
+	} else {
+		// Encounters from before enemy instances point straight at the blueprint
+		bp, ok := s.blueprints.Enemies[encounter.EnemyID.String()]
+		if !ok {
+			return nil, fmt.Errorf("enemy blueprint %s not found", encounter.EnemyID)
+		}
+		info.Enemy = bp
+	}
+
+	// Boss scripts and squad size live on the timeline node
+	if encounter.NodeID != nil {
+		node, err := s.repo.GetNodeByID(*encounter.NodeID)
+		if err == nil && node != nil {
+			info.IsScripted = node.IsScripted
+			info.ScriptEvents = node.ScriptEvents
+			info.EnemyCount = node.EnemyCount
+			info.Hazard = s.pilotHazardEffect(ctx, node.Hazard, expedition)
+		}
+	}
+
+	// The alarm calls in reinforcements
+	if extra := alarmReinforcements[expedition.AlarmTier()]; extra > 0 {
+		if info.EnemyCount < 1 {
+			info.EnemyCount = 1
+		}
+		info.EnemyCount += extra
+	}
+
+	// Loot is rolled up front from the enemy's ID, so retrying the fight cannot reroll it
+	if info.Enemy.LootTable != "" {
+		drop, err := s.blueprints.RollLoot(info.Enemy.LootTable, game.LootSeed(info.EnemyID.String()), info.EnemyCount)
+		if err != nil {
+			return nil, err
+		}
+		info.Loot = drop
+	}
+
+	return info, nil
+}
+
+// RecordBattleOutcome persists an enemy instance's HP after a battle and moves the expedition on:
+// a win clears the encounter's node, anything else fails the expedition.
+func (s *Service) RecordBattleOutcome(ctx context.Context, enemyID uuid.UUID, hp int, outcome combat.BattleOutcome) error {
+	enemy, err := s.repo.GetEnemyInstanceByID(enemyID)
+	if err != nil {
+		return err
+	}
+	if enemy == nil {
+		return nil // Legacy encounter fought against the blueprint directly
+	}
+	if outcome == combat.OutcomeVictory || hp < 0 {
+		hp = 0
+	}
+	if err := s.repo.UpdateEnemyInstanceHP(enemyID, hp); err != nil {
+		return err
+	}
+
+	expedition, err := s.repo.GetExpeditionByID(enemy.ExpeditionID)
+	if err != nil {
+		return err
+	}
+	if expedition.Status != ExpeditionInCombat {
+		return nil
+	}
+	if outcome != combat.OutcomeVictory {
+		return s.TransitionExpedition(ctx, expedition, ExpeditionFailed)
+	}
+
+	nodes, err := s.repo.GetNodesByExpeditionID(expedition.ID)
+	if err != nil {
+		return err
+	}
+	var node *Node
+	encounter, err := s.repo.GetEncounterByID(enemy.EncounterID)
+	if err != nil {
+		return err
+	}
+	if encounter != nil && encounter.NodeID != nil {
+		node = nodeByID(nodes, *encounter.NodeID)
+	}
+	return s.advancePast(ctx, expedition, node, nodes)
+}
+
 // ActivateSkill handles the usage of Neural Energy (NE) for active skills
 func (s *Service) ActivateSkill(ctx context.Context, userID uuid.UUID, skillName string) error {
 	// 1. Get Pilot Stats
@@ -1269,5 +1588,10 @@ func (s *Service) GenerateVisualPrompt(item *vehicle.Item, node *Node) string {
 		node.EnvironmentDescription,
 	)
 
+	// 6. Layer the node's hazard over the scene
+	if h, ok := s.blueprints.Hazards[string(node.Hazard)]; ok && h.VisualPrompt != "" {
+		prompt += ", " + h.VisualPrompt
+	}
+
 	return prompt
 }
@@ -1,10 +1,15 @@
 package exploration
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/combat"
 	"github.com/ryudokung/Project-0/backend/internal/game"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,6 +23,16 @@ func (m *MockRepo) CreateExpedition(expedition *Expedition) error {
 	return args.Error(0)
 }
 
+func (m *MockRepo) UpdateExpeditionStatus(id uuid.UUID, status ExpeditionStatus) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockRepo) UpdateExpeditionAlarm(id uuid.UUID, level int) error {
+	args := m.Called(id, level)
+	return args.Error(0)
+}
+
 func (m *MockRepo) GetExpeditionByID(id uuid.UUID) (*Expedition, error) {
 	args := m.Called(id)
 	return args.Get(0).(*Expedition), args.Error(1)
@@ -53,6 +68,11 @@ func (m *MockRepo) GetEncountersByExpeditionID(expeditionID uuid.UUID) ([]Encoun
 	return args.Get(0).([]Encounter), args.Error(1)
 }
 
+func (m *MockRepo) GetEncounterByID(id uuid.UUID) (*Encounter, error) {
+	args := m.Called(id)
+	return args.Get(0).(*Encounter), args.Error(1)
+}
+
 func (m *MockRepo) GetSessionByUserID(userID uuid.UUID) (*Session, error) {
 	args := m.Called(userID)
 	return args.Get(0).(*Session), args.Error(1)
@@ -73,6 +93,36 @@ func (m *MockRepo) GetPlanetLocationsBySubSectorID(subSectorID uuid.UUID) ([]Pla
 	return args.Get(0).([]PlanetLocation), args.Error(1)
 }
 
+func (m *MockRepo) GetSectorByID(id uuid.UUID) (*Sector, error) {
+	args := m.Called(id)
+	return args.Get(0).(*Sector), args.Error(1)
+}
+
+func (m *MockRepo) GetSubSectorByID(id uuid.UUID) (*SubSector, error) {
+	args := m.Called(id)
+	return args.Get(0).(*SubSector), args.Error(1)
+}
+
+func (m *MockRepo) GetPlanetLocationByID(id uuid.UUID) (*PlanetLocation, error) {
+	args := m.Called(id)
+	return args.Get(0).(*PlanetLocation), args.Error(1)
+}
+
+func (m *MockRepo) CreateEnemyInstance(enemy *EnemyInstance) error {
+	args := m.Called(enemy)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetEnemyInstanceByID(id uuid.UUID) (*EnemyInstance, error) {
+	args := m.Called(id)
+	return args.Get(0).(*EnemyInstance), args.Error(1)
+}
+
+func (m *MockRepo) UpdateEnemyInstanceHP(id uuid.UUID, hp int) error {
+	args := m.Called(id, hp)
+	return args.Error(0)
+}
+
 func TestGenerateTimeline(t *testing.T) {
 	blueprints := &game.BlueprintRegistry{
 		Nodes: map[string]game.NodeBlueprint{
@@ -98,3 +148,484 @@ func TestGenerateTimeline(t *testing.T) {
 		assert.NotEmpty(t, node.Zone)
 	}
 }
+
+func TestEnemyInstanceScaling(t *testing.T) {
+	bp := game.EnemyBlueprint{ID: "scout", Name: "Scout"}
+	bp.Stats.HP = 100
+	bp.Stats.Attack = 20
+	bp.Stats.Defense = 10
+	bp.Stats.Speed = 12
+
+	node := &Node{DifficultyMultiplier: 1.2, Hazard: HazardVoidEcho}
+	scale := EnemyScale(node, 3, &game.HazardEffect{EnemyScale: 1.25})
+	assert.InDelta(t, 1.2*1.25*1.5, scale, 1e-9)
+
+	enemy := NewEnemyInstance(bp, uuid.New(), uuid.New(), scale)
+	assert.Equal(t, 225, enemy.HP)
+	assert.Equal(t, enemy.HP, enemy.MaxHP)
+	assert.Equal(t, 45, enemy.Attack)
+	assert.Equal(t, 23, enemy.Defense)
+	assert.Equal(t, 12, enemy.Speed)
+	assert.Equal(t, "scout", enemy.BlueprintID)
+
+	// Unset multiplier and hazard leave the blueprint untouched
+	assert.Equal(t, 1.0, EnemyScale(&Node{}, 1, nil))
+}
+
+// stubGameRepo serves one pilot; other game.Repository methods are not used by these tests
+type stubGameRepo struct {
+	game.Repository
+	pilot *game.PilotStats
+}
+
+func (r *stubGameRepo) GetActivePilotStats(userID uuid.UUID) (*game.PilotStats, error) {
+	return r.pilot, nil
+}
+
+func (r *stubGameRepo) UpdatePilotStats(stats *game.PilotStats) error {
+	r.pilot = stats
+	return nil
+}
+
+// stubVehicleUseCase serves a fixed inventory; other vehicle.UseCase methods are not used by these tests
+type stubVehicleUseCase struct {
+	vehicle.UseCase
+	items []vehicle.Item
+}
+
+func (u *stubVehicleUseCase) GetItems(ctx context.Context, userID uuid.UUID) ([]vehicle.Item, error) {
+	return u.items, nil
+}
+
+func TestRecordBattleOutcome(t *testing.T) {
+	repo := new(MockRepo)
+	s := &Service{repo: repo}
+
+	// Victory mid-timeline: the enemy dies and exploring resumes
+	expedition := &Expedition{ID: uuid.New(), Status: ExpeditionInCombat}
+	nodes := []Node{{ID: uuid.New()}, {ID: uuid.New()}}
+	encounter := &Encounter{ID: uuid.New(), NodeID: &nodes[0].ID}
+	enemy := &EnemyInstance{ID: uuid.New(), ExpeditionID: expedition.ID, EncounterID: encounter.ID, HP: 80, MaxHP: 120}
+	repo.On("GetEnemyInstanceByID", enemy.ID).Return(enemy, nil)
+	repo.On("UpdateEnemyInstanceHP", enemy.ID, 0).Return(nil)
+	repo.On("GetExpeditionByID", expedition.ID).Return(expedition, nil)
+	repo.On("GetNodesByExpeditionID", expedition.ID).Return(nodes, nil)
+	repo.On("GetEncounterByID", encounter.ID).Return(encounter, nil)
+	repo.On("UpdateExpeditionStatus", expedition.ID, ExpeditionActive).Return(nil)
+	assert.NoError(t, s.RecordBattleOutcome(context.Background(), enemy.ID, 12, combat.OutcomeVictory))
+	assert.Equal(t, ExpeditionActive, expedition.Status)
+
+	// Defeat keeps the enemy's HP and fails the expedition
+	lost := &Expedition{ID: uuid.New(), Status: ExpeditionInCombat}
+	survivor := &EnemyInstance{ID: uuid.New(), ExpeditionID: lost.ID, HP: 80, MaxHP: 120}
+	repo.On("GetEnemyInstanceByID", survivor.ID).Return(survivor, nil)
+	repo.On("UpdateEnemyInstanceHP", survivor.ID, 40).Return(nil)
+	repo.On("GetExpeditionByID", lost.ID).Return(lost, nil)
+	repo.On("UpdateExpeditionStatus", lost.ID, ExpeditionFailed).Return(nil)
+	assert.NoError(t, s.RecordBattleOutcome(context.Background(), survivor.ID, 40, combat.OutcomeDefeat))
+	assert.Equal(t, ExpeditionFailed, lost.Status)
+
+	// Legacy encounters without an instance are left alone
+	legacyID := uuid.New()
+	repo.On("GetEnemyInstanceByID", legacyID).Return((*EnemyInstance)(nil), nil)
+	assert.NoError(t, s.RecordBattleOutcome(context.Background(), legacyID, 10, combat.OutcomeVictory))
+	repo.AssertNumberOfCalls(t, "UpdateEnemyInstanceHP", 2)
+}
+
+func TestExpeditionStateMachine(t *testing.T) {
+	assert.True(t, ExpeditionActive.CanTransition(ExpeditionInCombat))
+	assert.True(t, ExpeditionInCombat.CanTransition(ExpeditionExtracting))
+	assert.True(t, ExpeditionExtracting.CanTransition(ExpeditionCompleted))
+	assert.False(t, ExpeditionActive.CanTransition(ExpeditionCompleted))
+	assert.False(t, ExpeditionInCombat.CanTransition(ExpeditionAbandoned))
+	assert.False(t, ExpeditionCompleted.CanTransition(ExpeditionActive))
+
+	repo := new(MockRepo)
+	s := &Service{repo: repo}
+
+	// Finished expeditions reject every action
+	done := &Expedition{ID: uuid.New(), Status: ExpeditionCompleted}
+	repo.On("GetExpeditionByID", done.ID).Return(done, nil)
+	_, err := s.GenerateNewEncounter(context.Background(), done.ID, uuid.Nil, "")
+	assert.ErrorIs(t, err, ErrExpeditionFinished)
+	_, err = s.AbandonExpedition(context.Background(), done.ID)
+	assert.ErrorIs(t, err, ErrExpeditionFinished)
+
+	// Completing requires extraction first
+	active := &Expedition{ID: uuid.New(), Status: ExpeditionActive}
+	repo.On("GetExpeditionByID", active.ID).Return(active, nil)
+	_, err = s.CompleteExpedition(context.Background(), active.ID)
+	var transitionErr *TransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+
+	// A node cannot be resolved twice
+	resolved := &Node{ID: uuid.New(), ExpeditionID: active.ID, IsResolved: true}
+	repo.On("GetNodeByID", resolved.ID).Return(resolved, nil)
+	_, err = s.ResolveNode(context.Background(), resolved.ID)
+	assert.ErrorIs(t, err, ErrNodeAlreadyResolved)
+	_, err = s.ResolveNodeChoice(context.Background(), resolved.ID, "Proceed")
+	assert.ErrorIs(t, err, ErrNodeAlreadyResolved)
+
+	// Nodes cannot be resolved mid-fight
+	fighting := &Expedition{ID: uuid.New(), Status: ExpeditionInCombat}
+	open := &Node{ID: uuid.New(), ExpeditionID: fighting.ID}
+	repo.On("GetExpeditionByID", fighting.ID).Return(fighting, nil)
+	repo.On("GetNodeByID", open.ID).Return(open, nil)
+	_, err = s.ResolveNode(context.Background(), open.ID)
+	assert.ErrorIs(t, err, ErrCombatInProgress)
+
+	// Advancing needs the current node resolved, or its enemy beaten
+	exploring := &Expedition{ID: uuid.New(), Status: ExpeditionActive}
+	nodes := []Node{{ID: uuid.New(), Type: NodeResource}, {ID: uuid.New(), Type: NodeCombat}, {ID: uuid.New()}}
+	visited := []Encounter{{ID: uuid.New(), NodeID: &nodes[0].ID, Type: NodeResource}}
+	repo.On("GetExpeditionByID", exploring.ID).Return(exploring, nil)
+	repo.On("GetNodesByExpeditionID", exploring.ID).Return(nodes, nil)
+	repo.On("GetEncountersByExpeditionID", exploring.ID).Return(visited, nil).Once()
+	_, err = s.GenerateNewEncounter(context.Background(), exploring.ID, uuid.Nil, "")
+	assert.ErrorIs(t, err, ErrNodeUnresolved)
+
+	enemy := &EnemyInstance{ID: uuid.New(), HP: 30, MaxHP: 100}
+	nodes[0].IsResolved = true
+	fought := append(visited, Encounter{ID: uuid.New(), NodeID: &nodes[1].ID, Type: NodeCombat, EnemyID: &enemy.ID})
+	repo.On("GetEncountersByExpeditionID", exploring.ID).Return(fought, nil).Once()
+	repo.On("GetEnemyInstanceByID", enemy.ID).Return(enemy, nil)
+	_, err = s.GenerateNewEncounter(context.Background(), exploring.ID, uuid.Nil, "")
+	assert.ErrorIs(t, err, ErrCombatUnresolved)
+}
+
+func TestEndNodeCompletesOnceResolved(t *testing.T) {
+	repo := new(MockRepo)
+	pilot := &game.PilotStats{}
+	s := &Service{repo: repo, gameRepo: &stubGameRepo{pilot: pilot}}
+
+	expedition := &Expedition{ID: uuid.New(), Status: ExpeditionActive}
+	nodes := []Node{{ID: uuid.New(), ExpeditionID: expedition.ID}, {ID: uuid.New(), ExpeditionID: expedition.ID, IsEnd: true}}
+	end := &nodes[1]
+	repo.On("GetExpeditionByID", expedition.ID).Return(expedition, nil)
+	repo.On("UpdateExpeditionStatus", expedition.ID, ExpeditionExtracting).Return(nil)
+	repo.On("UpdateExpeditionStatus", expedition.ID, ExpeditionCompleted).Return(nil)
+	repo.On("GetNodeByID", end.ID).Return(end, nil)
+	repo.On("UpdateNode", end).Return(nil)
+
+	// Arriving at the end node waits for it to be resolved
+	assert.NoError(t, s.advancePast(context.Background(), expedition, end, nodes))
+	assert.Equal(t, ExpeditionExtracting, expedition.Status)
+
+	_, err := s.ResolveNode(context.Background(), end.ID)
+	assert.NoError(t, err)
+	assert.True(t, end.IsResolved)
+	assert.Equal(t, ExpeditionCompleted, expedition.Status)
+	assert.Equal(t, 1, pilot.ExpeditionsCompleted)
+}
+
+func TestChoiceLootRollsFromNodeTable(t *testing.T) {
+	blueprints := game.NewBlueprintRegistry()
+	blueprints.ItemTemplates["PLATING"] = game.ItemTemplate{ID: "PLATING", Name: "Plating", ItemType: "PART", RarityWeights: map[string]int{"COMMON": 1, "RARE": 1}}
+	blueprints.LootTables["SALVAGE"] = game.LootTable{
+		ID:         "SALVAGE",
+		Rolls:      2,
+		Guaranteed: []game.LootEntry{{Currency: game.CurrencyScrapMetal, Min: 10, Max: 20}},
+		Entries:    []game.LootEntry{{Weight: 1}, {Weight: 1, Item: "PLATING"}},
+	}
+	blueprints.Nodes["RESOURCE"] = game.NodeBlueprint{
+		ID: "RESOURCE", Type: "RESOURCE", LootTable: "SALVAGE",
+		Choices: []game.ChoiceBlueprint{{Label: "Scavenge"}, {Label: "Scan", LootTable: "OTHER"}},
+	}
+	s := &Service{blueprints: blueprints}
+
+	choices := s.GenerateChoicesForType(NodeResource)
+	assert.Equal(t, "SALVAGE", choices[0].LootTable)
+	assert.Equal(t, "OTHER", choices[1].LootTable)
+
+	seed := game.LootSeed(uuid.New().String(), choices[0].Label)
+	first, err := blueprints.RollLoot("SALVAGE", seed, 1)
+	assert.NoError(t, err)
+	again, _ := blueprints.RollLoot("SALVAGE", seed, 1)
+	assert.Equal(t, first, again)
+	assert.GreaterOrEqual(t, first.Currencies[game.CurrencyScrapMetal], 10)
+	assert.LessOrEqual(t, first.Currencies[game.CurrencyScrapMetal], 20)
+
+	_, err = blueprints.RollLoot("MISSING", seed, 1)
+	assert.Error(t, err)
+}
+
+func TestSelectNextNodeFollowsBranches(t *testing.T) {
+	nodes := []Node{
+		{ID: uuid.New(), BlueprintID: "start", NextNodes: []string{"left", "right"}},
+		{ID: uuid.New(), BlueprintID: "left", NextNodes: []string{"end"}},
+		{ID: uuid.New(), BlueprintID: "right", NextNodes: []string{"end"}},
+		{ID: uuid.New(), BlueprintID: "end", IsEnd: true},
+	}
+
+	first, err := selectNextNode(nodes, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "start", first.BlueprintID)
+
+	atStart := []Encounter{{NodeID: &nodes[0].ID}}
+	_, err = selectNextNode(nodes, atStart, "")
+	assert.ErrorIs(t, err, ErrBranchChoiceRequired)
+	_, err = selectNextNode(nodes, atStart, "end")
+	assert.ErrorIs(t, err, ErrInvalidNextNode)
+	right, err := selectNextNode(nodes, atStart, "right")
+	assert.NoError(t, err)
+	assert.Equal(t, "right", right.BlueprintID)
+
+	// A single way forward needs no choice; the end node finishes the expedition
+	atRight := append(atStart, Encounter{NodeID: &nodes[2].ID})
+	end, err := selectNextNode(nodes, atRight, "")
+	assert.NoError(t, err)
+	assert.True(t, isFinalNode(end, nodes))
+	_, err = selectNextNode(nodes, append(atRight, Encounter{NodeID: &nodes[3].ID}), "")
+	assert.ErrorIs(t, err, ErrExpeditionExtracting)
+
+	// Linear timelines still walk by position and end on their last node
+	linear := []Node{{ID: uuid.New()}, {ID: uuid.New()}}
+	next, err := selectNextNode(linear, []Encounter{{NodeID: &linear[0].ID}}, "ignored")
+	assert.NoError(t, err)
+	assert.Equal(t, linear[1].ID, next.ID)
+	assert.True(t, isFinalNode(next, linear))
+	next, _ = selectNextNode(linear, []Encounter{{}, {}}, "")
+	assert.Nil(t, next)
+}
+
+func TestExpeditionBlueprintGraphValidation(t *testing.T) {
+	type bpNode = game.ExpeditionNodeBlueprint
+	graph := func(nodes ...bpNode) game.ExpeditionBlueprint {
+		bp := game.ExpeditionBlueprint{ID: "test"}
+		bp.Nodes = nodes
+		return bp
+	}
+
+	assert.NoError(t, graph(
+		bpNode{ID: "a", NextNodes: []string{"b", "c"}},
+		bpNode{ID: "b", NextNodes: []string{"c"}},
+		bpNode{ID: "c", IsEnd: true},
+	).ValidateGraph())
+
+	assert.ErrorContains(t, graph(
+		bpNode{ID: "a", NextNodes: []string{"missing"}},
+	).ValidateGraph(), "unknown node")
+
+	assert.ErrorContains(t, graph(
+		bpNode{ID: "a", NextNodes: []string{"b"}},
+		bpNode{ID: "b", NextNodes: []string{"a", "c"}},
+		bpNode{ID: "c", IsEnd: true},
+	).ValidateGraph(), "cycle")
+
+	assert.ErrorContains(t, graph(
+		bpNode{ID: "a", NextNodes: []string{"c"}},
+		bpNode{ID: "b", NextNodes: []string{"c"}},
+		bpNode{ID: "c", IsEnd: true},
+	).ValidateGraph(), "unreachable")
+
+	assert.ErrorContains(t, graph(
+		bpNode{ID: "a", NextNodes: []string{"b"}},
+		bpNode{ID: "b"},
+	).ValidateGraph(), "dead end")
+}
+
+func TestSeededTimelineIsReproducible(t *testing.T) {
+	blueprints := &game.BlueprintRegistry{
+		Nodes: map[string]game.NodeBlueprint{
+			"STANDARD": {ID: "STANDARD", Name: "Quiet Sector", Type: "STANDARD"},
+			"DRIFT":    {ID: "DRIFT", Name: "Debris Drift", Type: "STANDARD"},
+			"OUTPOST":  {ID: "OUTPOST", Name: "Abandoned Outpost", Type: "OUTPOST"},
+			"COMBAT":   {ID: "COMBAT", Name: "Ambush", Type: "COMBAT"},
+		},
+	}
+	repo := new(MockRepo)
+	s := &Service{repo: repo, blueprints: blueprints}
+
+	expedition := &Expedition{ID: uuid.New(), Seed: 42, RadarLevel: 2, TimelineLength: 6}
+	first := s.GenerateSeededTimeline(expedition.ID, expedition.TimelineParams(), nil)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, first, s.GenerateSeededTimeline(expedition.ID, expedition.TimelineParams(), nil))
+	}
+	other := s.GenerateSeededTimeline(expedition.ID, TimelineParams{Seed: 43, Length: 6, RadarLevel: 2}, nil)
+	assert.NotEqual(t, first, other)
+
+	// The admin audit finds no drift for an untouched timeline and reports a tampered node
+	repo.On("GetExpeditionByID", expedition.ID).Return(expedition, nil)
+	stored := s.GenerateSeededTimeline(expedition.ID, expedition.TimelineParams(), nil)
+	stored[0].IsResolved = true // Progress is not drift
+	repo.On("GetNodesByExpeditionID", expedition.ID).Return(stored, nil).Once()
+	audit, err := s.RegenerateTimeline(context.Background(), expedition.ID)
+	assert.NoError(t, err)
+	assert.True(t, audit.Matches)
+
+	stored[2].Hazard = HazardVoidEcho
+	stored[2].DetectionThreshold = -1
+	repo.On("GetNodesByExpeditionID", expedition.ID).Return(stored, nil).Once()
+	audit, err = s.RegenerateTimeline(context.Background(), expedition.ID)
+	assert.NoError(t, err)
+	assert.False(t, audit.Matches)
+	assert.Contains(t, audit.Mismatches, fmt.Sprintf("node 2 detection_threshold: stored -1, regenerated %d", audit.Regenerated[2].DetectionThreshold))
+}
+
+func TestSiteShapesTimeline(t *testing.T) {
+	blueprints := &game.BlueprintRegistry{
+		Nodes: map[string]game.NodeBlueprint{
+			"STANDARD": {ID: "STANDARD", Name: "Quiet Sector", Type: "STANDARD"},
+			"OUTPOST":  {ID: "OUTPOST", Name: "Abandoned Outpost", Type: "OUTPOST"},
+		},
+		Hazards: map[string]game.HazardBlueprint{
+			"CORROSIVE_RAIN": {ID: "CORROSIVE_RAIN", Terrains: []string{"MINING"}, RequiresAtmosphere: true},
+			"SOLAR_FLARE":    {ID: "SOLAR_FLARE", Terrains: []string{"MINING", "SPACE"}},
+			"VOID_ECHO":      {ID: "VOID_ECHO", Terrains: []string{"SPACE"}},
+		},
+	}
+	repo := new(MockRepo)
+	s := &Service{repo: repo, blueprints: blueprints}
+
+	sector := &Sector{ID: uuid.New(), Name: "THE DEAD RIM", Difficulty: "EXTREME"}
+	subSector := &SubSector{ID: uuid.New(), SectorID: sector.ID, Type: "PLANET", Name: "Vulcanis", Terrain: TerrainDesert, DetectionThreshold: 400, AllowedModes: []string{"MECH", "TANK"}, RequiresAtmosphere: true}
+	location := &PlanetLocation{ID: uuid.New(), SubSectorID: subSector.ID, Name: "Magma Chamber", Rewards: []string{"Ancient Tech", "Nexus Cores"}, Terrain: TerrainMining, RequiresAtmosphere: true}
+	repo.On("GetSubSectorByID", subSector.ID).Return(subSector, nil)
+	repo.On("GetSectorByID", sector.ID).Return(sector, nil)
+	repo.On("GetPlanetLocationByID", location.ID).Return(location, nil)
+
+	site, err := s.LoadSite(&subSector.ID, &location.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, site.ExpeditionDifficulty())
+
+	title, _, goal := site.Briefing()
+	assert.Equal(t, "Descent: Magma Chamber", title)
+	assert.Equal(t, "Recover Ancient Tech and Nexus Cores from Magma Chamber and extract safely.", goal)
+
+	nodes := s.GenerateSeededTimeline(uuid.New(), TimelineParams{Seed: 7, Length: 8, RadarLevel: 1}, site)
+	for i, n := range nodes {
+		assert.Equal(t, TerrainMining, n.Terrain) // The location's terrain overrides the sub-sector's
+		assert.NotEqual(t, ZoneEVA, n.Zone)       // Vehicle-only site
+		assert.InDelta(t, 2.0*(1.0+float64(i)*0.1), n.DifficultyMultiplier, 1e-9)
+		assert.GreaterOrEqual(t, n.DetectionThreshold, 300)
+		assert.LessOrEqual(t, n.DetectionThreshold, 500)
+		if n.Hazard != HazardNone {
+			assert.Contains(t, []HazardType{HazardCorrosiveRain, HazardSolarFlare}, n.Hazard)
+		}
+	}
+	assert.Equal(t, ZoneOrbital, nodes[0].Zone)
+
+	// Regeneration uses the expedition's site snapshot, not the live records
+	var snapshot *Site
+	raw, _ := json.Marshal(site)
+	assert.NoError(t, json.Unmarshal(raw, &snapshot))
+	expedition := &Expedition{ID: uuid.New(), SubSectorID: &subSector.ID, PlanetLocationID: &location.ID, Seed: 7, RadarLevel: 1, TimelineLength: 8, SiteSnapshot: snapshot}
+	repo.On("GetExpeditionByID", expedition.ID).Return(expedition, nil)
+	repo.On("GetNodesByExpeditionID", expedition.ID).Return(s.GenerateSeededTimeline(expedition.ID, expedition.TimelineParams(), site), nil)
+	location.Terrain = TerrainSpace
+	audit, err := s.RegenerateTimeline(context.Background(), expedition.ID)
+	assert.NoError(t, err)
+	assert.True(t, audit.Matches, audit.Mismatches)
+
+	// A location outside the sub-sector is rejected
+	stray := &PlanetLocation{ID: uuid.New(), SubSectorID: uuid.New()}
+	repo.On("GetPlanetLocationByID", stray.ID).Return(stray, nil)
+	_, err = s.LoadSite(&subSector.ID, &stray.ID)
+	assert.Error(t, err)
+}
+
+func TestSiteAccess(t *testing.T) {
+	// The seeded station (init.sql) names its vehicle types, so heavy vehicles are turned away
+	station := &Site{SubSector: &SubSector{Name: "Outpost 01", AllowedModes: []string{"PILOT", "SPEEDER", "SHIP"}, SuitabilityPilot: 100, SuitabilityVehicle: 20}}
+	assert.NoError(t, station.CheckAccess(nil, ModePilot))
+	assert.NoError(t, station.CheckAccess(nil, "SPEEDER"))
+	assert.NoError(t, station.CheckAccess(nil, "SHIP"))
+	assert.ErrorIs(t, station.CheckAccess(nil, "TANK"), ErrModeNotAllowed)
+	assert.InDelta(t, 1.5, station.Suitability(true), 1e-9)
+	assert.InDelta(t, 0.7, station.Suitability(false), 1e-9)
+
+	// VEHICLE admits any vehicle type but not a pilot on foot
+	generic := &Site{SubSector: &SubSector{Name: "Dock", AllowedModes: []string{"VEHICLE"}}}
+	assert.NoError(t, generic.CheckAccess(nil, "SHIP"))
+	assert.NoError(t, generic.CheckAccess(nil, "TANK"))
+	assert.ErrorIs(t, generic.CheckAccess(nil, ModePilot), ErrModeNotAllowed)
+
+	// Atmospheric sites need entry research; the location's modes override the sub-sector's
+	planet := &Site{
+		SubSector: &SubSector{ID: uuid.New(), Name: "Krios Prime", Type: "PLANET", AllowedModes: []string{"MECH", "TANK"}, RequiresAtmosphere: true},
+		Location:  &PlanetLocation{Name: "Bunker Alpha", AllowedModes: []string{"PILOT", "EXOSUIT"}, RequiresAtmosphere: true},
+	}
+	pilot := &game.PilotStats{Metadata: map[string]interface{}{}}
+	assert.ErrorIs(t, planet.CheckAccess(pilot, "MECH"), ErrModeNotAllowed)
+	assert.ErrorIs(t, planet.CheckAccess(pilot, ModePilot), ErrAtmosphericEntryRequired)
+	pilot.Metadata["unlocked_research"] = []interface{}{"atmosphericEntry"}
+	assert.NoError(t, planet.CheckAccess(pilot, ModePilot, ModeExosuit))
+
+	var none *Site
+	assert.Equal(t, 1.0, none.Suitability(false))
+}
+
+func TestHazardEffects(t *testing.T) {
+	emp := game.HazardBlueprint{ID: "EMP_STORM", Name: "EMP Storm", SuccessChance: -0.2, Stress: 10, FuelDrain: 1.5, EnemyScale: 1.2, VisualPrompt: "arcs of lightning"}
+	emp.Combat.DisabledDamageTypes = []string{"ENERGY"}
+	emp.Combat.DamageMultipliers = map[string]float64{"KINETIC": 1.2}
+	emp.Counters = []game.HazardCounter{{Research: "hackingModule", Reduction: 0.5}, {Item: "Scanner Array", Reduction: 0.5}}
+	s := &Service{blueprints: &game.BlueprintRegistry{Hazards: map[string]game.HazardBlueprint{"EMP_STORM": emp}}}
+
+	pilot := &game.PilotStats{Metadata: map[string]interface{}{}}
+	full := s.HazardEffect(context.Background(), HazardEMPStorm, &Expedition{UserID: uuid.New()}, pilot)
+	assert.Equal(t, -0.2, full.SuccessChance)
+	assert.Equal(t, 1.5, full.FuelDrain)
+	assert.Equal(t, 1.0, full.O2Drain) // Omitted multipliers are neutral
+	assert.Equal(t, []string{"ENERGY"}, full.DisabledDamageTypes)
+
+	// Research halves the numbers but the EMP still knocks out Energy weapons
+	pilot.Metadata["unlocked_research"] = []interface{}{"hackingModule"}
+	half := s.HazardEffect(context.Background(), HazardEMPStorm, &Expedition{UserID: uuid.New()}, pilot)
+	assert.InDelta(t, -0.1, half.SuccessChance, 1e-9)
+	assert.Equal(t, 5, half.Stress)
+	assert.InDelta(t, 1.25, half.FuelDrain, 1e-9)
+	assert.InDelta(t, 1.1, half.DamageMultipliers["KINETIC"], 1e-9)
+	assert.NotEmpty(t, half.DisabledDamageTypes)
+
+	// Research plus the counter item neutralise it
+	none := emp.Effect(emp.Mitigation(pilot, []string{"Scanner Array"}))
+	assert.Equal(t, 1.0, none.Mitigation)
+	assert.Equal(t, 1.0, none.EnemyScale)
+	assert.Empty(t, none.DisabledDamageTypes)
+
+	// Counter items only count when fitted to the expedition's vehicle
+	shipID, spareID := uuid.New(), uuid.New()
+	s.vehicleUseCase = &stubVehicleUseCase{items: []vehicle.Item{{Name: "Scanner Array", IsEquipped: true, ParentItemID: &spareID}}}
+	onShip := &Expedition{UserID: uuid.New(), VehicleID: &shipID}
+	assert.Equal(t, 0.5, s.HazardEffect(context.Background(), HazardEMPStorm, onShip, pilot).Mitigation)
+	s.vehicleUseCase = &stubVehicleUseCase{items: []vehicle.Item{{Name: "Scanner Array", IsEquipped: true, ParentItemID: &shipID}}}
+	assert.Equal(t, 1.0, s.HazardEffect(context.Background(), HazardEMPStorm, onShip, pilot).Mitigation)
+
+	assert.Nil(t, s.HazardEffect(context.Background(), HazardNone, &Expedition{UserID: uuid.New()}, pilot))
+	assert.Contains(t, s.GenerateVisualPrompt(nil, &Node{Hazard: HazardEMPStorm}), "arcs of lightning")
+}
+
+func TestAlarmLevel(t *testing.T) {
+	assert.Equal(t, 25, NextAlarmLevel(0, ApproachPassive, true))
+	assert.Equal(t, 40, NextAlarmLevel(0, ApproachLoud, true))
+	assert.Equal(t, 15, NextAlarmLevel(0, ApproachLoud, false))
+	assert.Equal(t, 10, NextAlarmLevel(30, ApproachStealth, false))
+	assert.Equal(t, 55, NextAlarmLevel(30, ApproachStealth, true)) // Caught sneaking: no decay
+	assert.Equal(t, 0, NextAlarmLevel(5, ApproachStealth, false))
+	assert.Equal(t, 100, NextAlarmLevel(90, ApproachLoud, true))
+
+	assert.Equal(t, AlarmCalm, AlarmTierFor(0))
+	assert.Equal(t, AlarmSuspicious, AlarmTierFor(25))
+	assert.Equal(t, AlarmAlert, AlarmTierFor(50))
+	assert.Equal(t, AlarmLockdown, AlarmTierFor(100))
+
+	// Quiet nodes only escalate from ALERT on, and once escalated stay escalated as the alarm rises
+	nodes := make([]Node, 20)
+	for i := range nodes {
+		nodes[i] = Node{ID: uuid.New(), Type: NodeStandard, PositionIndex: i}
+	}
+	nodes[len(nodes)-1].Type = NodeOutpost
+	escalated := 0
+	for i := range nodes[:len(nodes)-1] {
+		assert.False(t, escalatesToCombat(&nodes[i], nodes, 49))
+		if escalatesToCombat(&nodes[i], nodes, 75) {
+			escalated++
+			assert.True(t, escalatesToCombat(&nodes[i], nodes, 100))
+		}
+	}
+	assert.Greater(t, escalated, 0)
+	assert.False(t, escalatesToCombat(&nodes[len(nodes)-1], nodes, 100)) // Never the final node
+	boss := Node{ID: uuid.New(), Type: NodeBoss}
+	assert.False(t, escalatesToCombat(&boss, nodes, 100))
+}
@@ -0,0 +1,295 @@
+package exploration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+var (
+	// ErrModeNotAllowed means the deployed vehicle type (or going on foot) is not in the site's AllowedModes
+	ErrModeNotAllowed = errors.New("deployment mode not allowed at this site")
+	// ErrAtmosphericEntryRequired means the site needs the atmosphericEntry (or quantumGate) research
+	ErrAtmosphericEntryRequired = errors.New("atmospheric entry research required")
+)
+
+// Deployment modes that are not vehicle types
+const (
+	ModePilot   = "PILOT"
+	ModeExosuit = "EXOSUIT"
+	// ModeVehicle in AllowedModes admits any vehicle type
+	ModeVehicle = "VEHICLE"
+)
+
+// atmosphereUnlocks are the research projects that allow landing on atmospheric sites
+var atmosphereUnlocks = []string{"atmosphericEntry", "quantumGate"}
+
+// Site is the map location an expedition is generated for: the sub-sector, its sector and
+// optionally a planet location, whose fields override the sub-sector's.
+type Site struct {
+	Sector    *Sector         `json:"sector,omitempty"`
+	SubSector *SubSector      `json:"sub_sector,omitempty"`
+	Location  *PlanetLocation `json:"planet_location,omitempty"`
+}
+
+// sectorDifficulty maps Sector.Difficulty to the base node difficulty multiplier and expedition difficulty
+var sectorDifficulty = map[string]struct {
+	Multiplier   float64
+	Expedition   int
+	HazardChance float64
+}{
+	"LOW":     {Multiplier: 1.0, Expedition: 1, HazardChance: 0.2},
+	"MEDIUM":  {Multiplier: 1.2, Expedition: 1, HazardChance: 0.3},
+	"HIGH":    {Multiplier: 1.5, Expedition: 2, HazardChance: 0.4},
+	"EXTREME": {Multiplier: 2.0, Expedition: 3, HazardChance: 0.5},
+}
+
+// siteTypeNodes are the procedural node pools per sub-sector type; repeats weight the draw
+var siteTypeNodes = map[string][]NodeType{
+	"PLANET":  {NodeStandard, NodeResource, NodeResource, NodeCombat, NodeCombat, NodeAnomaly, NodeNarrative},
+	"STATION": {NodeStandard, NodeStandard, NodeResource, NodeCombat, NodeNarrative, NodeNarrative},
+	"WRECK":   {NodeStandard, NodeResource, NodeResource, NodeResource, NodeCombat, NodeAnomaly},
+	"ANOMALY": {NodeStandard, NodeCombat, NodeAnomaly, NodeAnomaly, NodeAnomaly, NodeNarrative},
+}
+
+// defaultNodePool is used when there is no site or its type is unknown
+var defaultNodePool = []NodeType{NodeStandard, NodeResource, NodeCombat, NodeAnomaly, NodeNarrative}
+
+// onFootModes are the AllowedModes that can leave a vehicle and go EVA
+var onFootModes = map[string]bool{ModePilot: true, ModeExosuit: true}
+
+// LoadSite fetches the sector records a timeline is generated from. It returns nil when no sub-sector is given.
+func (s *Service) LoadSite(subSectorID, planetLocationID *uuid.UUID) (*Site, error) {
+	if subSectorID == nil || *subSectorID == uuid.Nil {
+		return nil, nil
+	}
+	subSector, err := s.repo.GetSubSectorByID(*subSectorID)
+	if err != nil {
+		return nil, err
+	}
+	if subSector == nil {
+		return nil, fmt.Errorf("sub-sector %s not found", *subSectorID)
+	}
+	site := &Site{SubSector: subSector}
+
+	if site.Sector, err = s.repo.GetSectorByID(subSector.SectorID); err != nil {
+		return nil, err
+	}
+	if planetLocationID != nil && *planetLocationID != uuid.Nil {
+		location, err := s.repo.GetPlanetLocationByID(*planetLocationID)
+		if err != nil {
+			return nil, err
+		}
+		if location == nil || location.SubSectorID != subSector.ID {
+			return nil, fmt.Errorf("planet location %s not found in sub-sector %s", *planetLocationID, subSector.ID)
+		}
+		site.Location = location
+	}
+	return site, nil
+}
+
+// Name is the most specific name of the site
+func (s *Site) Name() string {
+	if s.Location != nil {
+		return s.Location.Name
+	}
+	return s.SubSector.Name
+}
+
+// Terrain is the location's terrain, falling back to the sub-sector's
+func (s *Site) Terrain() TerrainType {
+	if s.Location != nil && s.Location.Terrain != "" {
+		return s.Location.Terrain
+	}
+	if s.SubSector.Terrain != "" {
+		return s.SubSector.Terrain
+	}
+	return TerrainSpace
+}
+
+// DetectionThreshold is the site's base threshold before radar and per-node variance
+func (s *Site) DetectionThreshold() int {
+	if s.Location != nil && s.Location.DetectionThreshold > 0 {
+		return s.Location.DetectionThreshold
+	}
+	return s.SubSector.DetectionThreshold
+}
+
+// RequiresAtmosphere reports whether the site is an atmospheric surface
+func (s *Site) RequiresAtmosphere() bool {
+	if s.Location != nil {
+		return s.Location.RequiresAtmosphere
+	}
+	return s.SubSector.RequiresAtmosphere
+}
+
+// AllowedModes are the location's modes, falling back to the sub-sector's
+func (s *Site) AllowedModes() []string {
+	if s.Location != nil && len(s.Location.AllowedModes) > 0 {
+		return s.Location.AllowedModes
+	}
+	return s.SubSector.AllowedModes
+}
+
+// Rewards are the location's rewards, falling back to the sub-sector's
+func (s *Site) Rewards() []string {
+	if s.Location != nil && len(s.Location.Rewards) > 0 {
+		return s.Location.Rewards
+	}
+	return s.SubSector.Rewards
+}
+
+// modes reports whether a pilot or exosuit may operate at the site (onFoot), and whether any vehicle may
+func (s *Site) modes() (onFoot, vehicle bool) {
+	modes := s.AllowedModes()
+	if len(modes) == 0 {
+		return true, true
+	}
+	for _, m := range modes {
+		if onFootModes[strings.ToUpper(m)] {
+			onFoot = true
+		} else {
+			vehicle = true
+		}
+	}
+	return onFoot, vehicle
+}
+
+// Allows reports whether one of the deployment modes is admitted. Empty AllowedModes admit everything.
+func (s *Site) Allows(modes ...string) bool {
+	allowed := s.AllowedModes()
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		a = strings.ToUpper(a)
+		for _, m := range modes {
+			m = strings.ToUpper(m)
+			if a == m || (a == ModeVehicle && !onFootModes[m]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckAccess rejects deployments whose modes the site does not allow, and atmospheric sites
+// the pilot has no entry research for
+func (s *Site) CheckAccess(pilot *game.PilotStats, modes ...string) error {
+	if !s.Allows(modes...) {
+		return fmt.Errorf("%w: %s accepts %s, not %s", ErrModeNotAllowed, s.Name(), strings.Join(s.AllowedModes(), ", "), strings.Join(modes, "/"))
+	}
+	if s.RequiresAtmosphere() {
+		for _, id := range atmosphereUnlocks {
+			if pilot != nil && pilot.HasUnlocked(id) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrAtmosphericEntryRequired, s.Name())
+	}
+	return nil
+}
+
+// Suitability turns the site's 0-100 suitability score for the deployment into an ECP multiplier:
+// 50 (the default) is neutral, 0 halves and 100 adds half again
+func (s *Site) Suitability(onFoot bool) float64 {
+	if s == nil {
+		return 1.0
+	}
+	score := s.SubSector.SuitabilityVehicle
+	if onFoot {
+		score = s.SubSector.SuitabilityPilot
+	}
+	if s.Location != nil {
+		score = s.Location.SuitabilityVehicle
+		if onFoot {
+			score = s.Location.SuitabilityPilot
+		}
+	}
+	return 0.5 + float64(score)/100.0
+}
+
+func (s *Site) difficulty() (multiplier float64, expedition int, hazardChance float64) {
+	if s != nil && s.Sector != nil {
+		if d, ok := sectorDifficulty[strings.ToUpper(s.Sector.Difficulty)]; ok {
+			return d.Multiplier, d.Expedition, d.HazardChance
+		}
+	}
+	return 1.0, 1, 0.3
+}
+
+// ExpeditionDifficulty is the Expedition.Difficulty the sector's difficulty maps to
+func (s *Site) ExpeditionDifficulty() int {
+	_, d, _ := s.difficulty()
+	return d
+}
+
+// nodePool is the node types drawn for the site's middle nodes
+func (s *Site) nodePool() []NodeType {
+	if s == nil {
+		return defaultNodePool
+	}
+	pool, ok := siteTypeNodes[strings.ToUpper(s.SubSector.Type)]
+	if !ok {
+		pool = defaultNodePool
+	}
+	// Every advertised reward makes resource nodes a little more common
+	extra := make([]NodeType, 0, len(pool)+len(s.Rewards()))
+	extra = append(extra, pool...)
+	for range s.Rewards() {
+		extra = append(extra, NodeResource)
+	}
+	return extra
+}
+
+// fitZone swaps zones the site's modes cannot enter: EVA needs an on-foot mode, orbital drops need a vehicle
+func (s *Site) fitZone(zone ZoneType) ZoneType {
+	if s == nil {
+		return zone
+	}
+	onFoot, vehicle := s.modes()
+	switch {
+	case zone == ZoneEVA && !onFoot:
+		return ZoneSurface
+	case zone == ZoneOrbital && !vehicle:
+		return ZoneCorridor
+	}
+	return zone
+}
+
+// Briefing returns the title, description and goal of an expedition to the site
+func (s *Site) Briefing() (title, description, goal string) {
+	name := s.Name()
+	sectorName := "uncharted space"
+	if s.Sector != nil {
+		sectorName = s.Sector.Name
+	}
+
+	title = fmt.Sprintf("Expedition: %s", name)
+	switch {
+	case s.Location != nil:
+		title = fmt.Sprintf("Descent: %s", name)
+	case strings.EqualFold(s.SubSector.Type, "WRECK"):
+		title = fmt.Sprintf("Salvage Run: %s", name)
+	case strings.EqualFold(s.SubSector.Type, "STATION"):
+		title = fmt.Sprintf("Docking Run: %s", name)
+	case strings.EqualFold(s.SubSector.Type, "ANOMALY"):
+		title = fmt.Sprintf("Anomaly Survey: %s", name)
+	}
+
+	description = fmt.Sprintf("%s (%s)", name, sectorName)
+	if s.Location != nil && s.Location.Description != "" {
+		description = fmt.Sprintf("%s — %s", description, s.Location.Description)
+	} else if s.SubSector.Description != "" {
+		description = fmt.Sprintf("%s — %s", description, s.SubSector.Description)
+	}
+
+	goal = fmt.Sprintf("Survey %s and extract safely.", name)
+	if rewards := s.Rewards(); len(rewards) > 0 {
+		goal = fmt.Sprintf("Recover %s from %s and extract safely.", strings.Join(rewards, " and "), name)
+	}
+	return title, description, goal
+}
@@ -0,0 +1,160 @@
+package exploration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ExpeditionStatus is the server-side lifecycle of an expedition
+type ExpeditionStatus string
+
+const (
+	ExpeditionActive     ExpeditionStatus = "ACTIVE"     // Advancing through the timeline
+	ExpeditionInCombat   ExpeditionStatus = "IN_COMBAT"  // A spawned enemy must be beaten before anything else
+	ExpeditionExtracting ExpeditionStatus = "EXTRACTING" // Final node reached; waiting to return to the Bastion
+	ExpeditionCompleted  ExpeditionStatus = "COMPLETED"
+	ExpeditionFailed     ExpeditionStatus = "FAILED"
+	ExpeditionAbandoned  ExpeditionStatus = "ABANDONED"
+)
+
+// expeditionTransitions lists the statuses each status may move to; terminal statuses have none
+var expeditionTransitions = map[ExpeditionStatus][]ExpeditionStatus{
+	ExpeditionActive:     {ExpeditionInCombat, ExpeditionExtracting, ExpeditionFailed, ExpeditionAbandoned},
+	ExpeditionInCombat:   {ExpeditionActive, ExpeditionExtracting, ExpeditionFailed},
+	ExpeditionExtracting: {ExpeditionCompleted, ExpeditionFailed, ExpeditionAbandoned},
+}
+
+var (
+	ErrExpeditionFinished   = errors.New("expedition is already finished")
+	ErrCombatInProgress     = errors.New("expedition is in combat")
+	ErrExpeditionExtracting = errors.New("expedition is extracting; no nodes left to advance to")
+	ErrCombatUnresolved     = errors.New("current combat encounter not resolved")
+	ErrNodeUnresolved       = errors.New("current node not resolved")
+	ErrNodeAlreadyResolved  = errors.New("node already resolved")
+)
+
+// TransitionError is returned for a status change the state machine does not allow
+type TransitionError struct {
+	From ExpeditionStatus
+	To   ExpeditionStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("expedition cannot go from %s to %s", e.From, e.To)
+}
+
+// IsFinished reports whether the status is terminal
+func (st ExpeditionStatus) IsFinished() bool {
+	return st == ExpeditionCompleted || st == ExpeditionFailed || st == ExpeditionAbandoned
+}
+
+// CanTransition reports whether the state machine allows moving from st to next
+func (st ExpeditionStatus) CanTransition(next ExpeditionStatus) bool {
+	for _, allowed := range expeditionTransitions[st] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCanAdvance rejects timeline actions unless the expedition is exploring
+func checkCanAdvance(e *Expedition) error {
+	switch {
+	case e.Status.IsFinished():
+		return ErrExpeditionFinished
+	case e.Status == ExpeditionInCombat:
+		return ErrCombatInProgress
+	case e.Status == ExpeditionExtracting:
+		return ErrExpeditionExtracting
+	}
+	return nil
+}
+
+// checkCanResolve rejects node choices while fighting or after the expedition ended.
+// The final node can still be resolved while extracting.
+func checkCanResolve(e *Expedition) error {
+	switch {
+	case e.Status.IsFinished():
+		return ErrExpeditionFinished
+	case e.Status == ExpeditionInCombat:
+		return ErrCombatInProgress
+	}
+	return nil
+}
+
+// TransitionExpedition moves an expedition to a new status and persists it.
+// Completing an expedition counts towards the pilot's ExpeditionsCompleted.
+func (s *Service) TransitionExpedition(ctx context.Context, e *Expedition, to ExpeditionStatus) error {
+	if !e.Status.CanTransition(to) {
+		if e.Status.IsFinished() {
+			return ErrExpeditionFinished
+		}
+		return &TransitionError{From: e.Status, To: to}
+	}
+	if err := s.repo.UpdateExpeditionStatus(e.ID, to); err != nil {
+		return err
+	}
+	e.Status = to
+
+	if to == ExpeditionCompleted {
+		stats, err := s.gameRepo.GetActivePilotStats(e.UserID)
+		if err != nil {
+			return err
+		}
+		if stats != nil {
+			stats.ExpeditionsCompleted++
+			if err := s.gameRepo.UpdatePilotStats(stats); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CompleteExpedition returns an extracting expedition to the Bastion
+func (s *Service) CompleteExpedition(ctx context.Context, expeditionID uuid.UUID) (*Expedition, error) {
+	expedition, err := s.repo.GetExpeditionByID(expeditionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TransitionExpedition(ctx, expedition, ExpeditionCompleted); err != nil {
+		return nil, err
+	}
+	return expedition, nil
+}
+
+// AbandonExpedition ends an expedition early without counting it as completed
+func (s *Service) AbandonExpedition(ctx context.Context, expeditionID uuid.UUID) (*Expedition, error) {
+	expedition, err := s.repo.GetExpeditionByID(expeditionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TransitionExpedition(ctx, expedition, ExpeditionAbandoned); err != nil {
+		return nil, err
+	}
+	return expedition, nil
+}
+
+// advancePast moves the expedition on once node has been cleared: back to exploring after a fight,
+// and to extraction after the final node. An IsEnd node completes the expedition once it is resolved.
+func (s *Service) advancePast(ctx context.Context, e *Expedition, node *Node, nodes []Node) error {
+	if node == nil || !isFinalNode(node, nodes) {
+		if e.Status == ExpeditionInCombat {
+			return s.TransitionExpedition(ctx, e, ExpeditionActive)
+		}
+		return nil
+	}
+	return s.TransitionExpedition(ctx, e, ExpeditionExtracting)
+}
+
+// completeAfter finishes an extracting expedition once its IsEnd node has been resolved
+func (s *Service) completeAfter(ctx context.Context, e *Expedition, node *Node) error {
+	if !node.IsEnd || e.Status != ExpeditionExtracting {
+		return nil
+	}
+	return s.TransitionExpedition(ctx, e, ExpeditionCompleted)
+}
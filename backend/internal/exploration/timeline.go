@@ -0,0 +1,126 @@
+package exploration
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/game"
+)
+
+// proceduralTimelineLength is the node count of new procedural expeditions
+const proceduralTimelineLength = 6
+
+// TimelineParams are every input of procedural timeline generation
+type TimelineParams struct {
+	Seed             int64      `json:"seed"`
+	Length           int        `json:"length"`
+	RadarLevel       int        `json:"radar_level"`
+	SubSectorID      *uuid.UUID `json:"sub_sector_id,omitempty"`
+	PlanetLocationID *uuid.UUID `json:"planet_location_id,omitempty"`
+}
+
+// TimelineParams returns the stored inputs the expedition's timeline was generated from
+func (e *Expedition) TimelineParams() TimelineParams {
+	return TimelineParams{
+		Seed:             e.Seed,
+		Length:           e.TimelineLength,
+		RadarLevel:       e.RadarLevel,
+		SubSectorID:      e.SubSectorID,
+		PlanetLocationID: e.PlanetLocationID,
+	}
+}
+
+// timelineNodeID derives a stable node ID from the expedition and position
+func timelineNodeID(expeditionID uuid.UUID, index int) uuid.UUID {
+	return uuid.NewSHA1(expeditionID, []byte(fmt.Sprintf("node-%d", index)))
+}
+
+// pickNodeBlueprint chooses among the blueprints of a node type in ID order, so map iteration cannot change the result
+func (s *Service) pickNodeBlueprint(t NodeType, rng *rand.Rand) (game.NodeBlueprint, bool) {
+	var ids []string
+	for id, b := range s.blueprints.Nodes {
+		if b.Type == string(t) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return game.NodeBlueprint{}, false
+	}
+	sort.Strings(ids)
+	return s.blueprints.Nodes[ids[rng.Intn(len(ids))]], true
+}
+
+// TimelineAudit compares an expedition's stored timeline with one regenerated from its stored params
+type TimelineAudit struct {
+	ExpeditionID uuid.UUID      `json:"expedition_id"`
+	Params       TimelineParams `json:"params"`
+	Site         *Site          `json:"site,omitempty"`
+	Matches      bool           `json:"matches"`
+	Mismatches   []string       `json:"mismatches,omitempty"`
+	Stored       []Node         `json:"stored"`
+	Regenerated  []Node         `json:"regenerated"`
+}
+
+// RegenerateTimeline rebuilds a procedural expedition's timeline from its seed and reports any drift from what is stored.
+// Progress fields (resolution) are not compared.
+func (s *Service) RegenerateTimeline(ctx context.Context, expeditionID uuid.UUID) (*TimelineAudit, error) {
+	expedition, err := s.repo.GetExpeditionByID(expeditionID)
+	if err != nil {
+		return nil, err
+	}
+	if expedition.Seed == 0 || expedition.TimelineLength == 0 {
+		return nil, fmt.Errorf("expedition %s has no procedural timeline seed", expeditionID)
+	}
+	stored, err := s.repo.GetNodesByExpeditionID(expeditionID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := expedition.TimelineParams()
+	site := expedition.SiteSnapshot
+	if site == nil {
+		// Expeditions from before site snapshots only have the live records to go on
+		if site, err = s.LoadSite(params.SubSectorID, params.PlanetLocationID); err != nil {
+			return nil, err
+		}
+	}
+	regenerated := s.GenerateSeededTimeline(expeditionID, params, site)
+	mismatches := compareTimelines(stored, regenerated)
+	return &TimelineAudit{
+		ExpeditionID: expeditionID,
+		Params:       params,
+		Site:         site,
+		Matches:      len(mismatches) == 0,
+		Mismatches:   mismatches,
+		Stored:       stored,
+		Regenerated:  regenerated,
+	}, nil
+}
+
+func compareTimelines(stored, regenerated []Node) []string {
+	var diffs []string
+	if len(stored) != len(regenerated) {
+		diffs = append(diffs, fmt.Sprintf("node count: stored %d, regenerated %d", len(stored), len(regenerated)))
+	}
+	for i := 0; i < len(stored) && i < len(regenerated); i++ {
+		a, b := stored[i], regenerated[i]
+		field := func(name string, x, y interface{}) {
+			if fmt.Sprint(x) != fmt.Sprint(y) {
+				diffs = append(diffs, fmt.Sprintf("node %d %s: stored %v, regenerated %v", i, name, x, y))
+			}
+		}
+		field("id", a.ID, b.ID)
+		field("blueprint", a.BlueprintID, b.BlueprintID)
+		field("type", a.Type, b.Type)
+		field("zone", a.Zone, b.Zone)
+		field("hazard", a.Hazard, b.Hazard)
+		field("terrain", a.Terrain, b.Terrain)
+		field("difficulty", fmt.Sprintf("%.2f", a.DifficultyMultiplier), fmt.Sprintf("%.2f", b.DifficultyMultiplier))
+		field("detection_threshold", a.DetectionThreshold, b.DetectionThreshold)
+		field("choices", len(a.Choices), len(b.Choices))
+	}
+	return diffs
+}
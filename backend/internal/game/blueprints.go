@@ -20,9 +20,13 @@ type NodeBlueprint struct {
 		Fuel float64 `yaml:"fuel"`
 		O2   float64 `yaml:"o2"`
 	} `yaml:"resource_costs"`
-	Choices []ChoiceBlueprint `yaml:"choices"`
+	Choices   []ChoiceBlueprint `yaml:"choices"`
+	LootTable string            `yaml:"loot_table,omitempty"` // Default for choices without their own
 }
 
+// choiceApproaches are the approaches exploration understands (see exploration.ApproachType); empty means none
+var choiceApproaches = map[string]bool{"": true, "PASSIVE_SCAN": true, "DEEP_ANALYSIS": true, "STEALTH": true, "LOUD": true}
+
 type ChoiceBlueprint struct {
 	Label         string   `yaml:"label"`
 	Description   string   `yaml:"description"`
@@ -30,6 +34,8 @@ type ChoiceBlueprint struct {
 	Rewards       []string `yaml:"rewards"`
 	Risks         []string `yaml:"risks"`
 	Requirements  []string `yaml:"requirements"`
+	LootTable     string   `yaml:"loot_table,omitempty"` // Rolled when the choice succeeds
+	Approach      string   `yaml:"approach,omitempty"`   // PASSIVE_SCAN, DEEP_ANALYSIS, STEALTH or LOUD
 }
 
 type EnemyBlueprint struct {
@@ -37,8 +43,10 @@ type EnemyBlueprint struct {
 	Name   string `yaml:"name"`
 	Type   string `yaml:"type"`
 	Class  string `yaml:"class"`
+	ArmorType string `yaml:"armor_type,omitempty"` // Overrides the balance config's class armor (KINETIC, ENERGY, EXPLOSIVE, VOID)
 	Rarity string `yaml:"rarity"`
 	CR     int    `yaml:"cr"`
+	LootTable string `yaml:"loot_table,omitempty"` // Rolled once per unit when the fight is won
 	Stats  struct {
 		HP      int `yaml:"hp"`
 		Attack  int `yaml:"attack"`
@@ -48,40 +56,49 @@ type EnemyBlueprint struct {
 }
 
 type ExpeditionBlueprint struct {
-	ID          string `yaml:"id"`
-	Title       string `yaml:"title"`
-	Description string `yaml:"description"`
-	Type        string `yaml:"type"`
-	Difficulty  int    `yaml:"difficulty"`
-	MinLevel    int    `yaml:"min_level"`
-	Nodes       []struct {
-		ID                     string        `yaml:"id"`
-		Type                   string        `yaml:"type"`
-		Title                  string        `yaml:"title"`
-		Description            string        `yaml:"description"`
-		ResourceType           string        `yaml:"resource_type,omitempty"`
-		Amount                 int           `yaml:"amount,omitempty"`
-		EnemyBlueprint         string        `yaml:"enemy_blueprint,omitempty"`
-		EnemyCount             int           `yaml:"enemy_count,omitempty"`
-		IsScripted             bool          `yaml:"is_scripted,omitempty"`
-		ScriptEvents           []ScriptEvent `yaml:"script_events,omitempty"`
-		NextNodes              []string      `yaml:"next_nodes,omitempty"`
-		IsEnd                  bool          `yaml:"is_end,omitempty"`
-		EnvironmentDescription string        `yaml:"environment_description,omitempty"`
-	} `yaml:"nodes"`
+	ID          string                    `yaml:"id"`
+	Title       string                    `yaml:"title"`
+	Description string                    `yaml:"description"`
+	Type        string                    `yaml:"type"`
+	Difficulty  int                       `yaml:"difficulty"`
+	MinLevel    int                       `yaml:"min_level"`
+	Nodes       []ExpeditionNodeBlueprint `yaml:"nodes"` // Nodes[0] is the start of the graph
+}
+
+// ExpeditionNodeBlueprint is one node of a handcrafted expedition graph
+type ExpeditionNodeBlueprint struct {
+	ID                     string        `yaml:"id"`
+	Type                   string        `yaml:"type"`
+	Title                  string        `yaml:"title"`
+	Description            string        `yaml:"description"`
+	ResourceType           string        `yaml:"resource_type,omitempty"`
+	Amount                 int           `yaml:"amount,omitempty"`
+	EnemyBlueprint         string        `yaml:"enemy_blueprint,omitempty"`
+	EnemyCount             int           `yaml:"enemy_count,omitempty"`
+	IsScripted             bool          `yaml:"is_scripted,omitempty"`
+	ScriptEvents           []ScriptEvent `yaml:"script_events,omitempty"`
+	NextNodes              []string      `yaml:"next_nodes,omitempty"`
+	IsEnd                  bool          `yaml:"is_end,omitempty"`
+	EnvironmentDescription string        `yaml:"environment_description,omitempty"`
 }
 
 type BlueprintRegistry struct {
-	Nodes       map[string]NodeBlueprint
-	Enemies     map[string]EnemyBlueprint
-	Expeditions map[string]ExpeditionBlueprint
+	Nodes         map[string]NodeBlueprint
+	Enemies       map[string]EnemyBlueprint
+	Expeditions   map[string]ExpeditionBlueprint
+	LootTables    map[string]LootTable
+	ItemTemplates map[string]ItemTemplate
+	Hazards       map[string]HazardBlueprint
 }
 
 func NewBlueprintRegistry() *BlueprintRegistry {
 	return &BlueprintRegistry{
-		Nodes:       make(map[string]NodeBlueprint),
-		Enemies:     make(map[string]EnemyBlueprint),
-		Expeditions: make(map[string]ExpeditionBlueprint),
+		Nodes:         make(map[string]NodeBlueprint),
+		Enemies:       make(map[string]EnemyBlueprint),
+		Expeditions:   make(map[string]ExpeditionBlueprint),
+		LootTables:    make(map[string]LootTable),
+		ItemTemplates: make(map[string]ItemTemplate),
+		Hazards:       make(map[string]HazardBlueprint),
 	}
 }
 
@@ -99,6 +116,22 @@ func (r *BlueprintRegistry) LoadExpeditions(path string) error {
 		return err
 	}
 
+	// Reject the whole file if any node graph or boss script is malformed
+	for _, exp := range config.Expeditions {
+		if err := exp.ValidateGraph(); err != nil {
+			return fmt.Errorf("expedition %s: %w", exp.ID, err)
+		}
+	}
+	for _, exp := range config.Expeditions {
+		for _, node := range exp.Nodes {
+			for i, event := range node.ScriptEvents {
+				if err := event.Validate(); err != nil {
+					return fmt.Errorf("expedition %s node %s script event %d: %w", exp.ID, node.ID, i, err)
+				}
+			}
+		}
+	}
+
 	for _, exp := range config.Expeditions {
 		r.Expeditions[exp.ID] = exp
 	}
@@ -121,6 +154,13 @@ func (r *BlueprintRegistry) LoadNodes(path string) error {
 		return err
 	}
 
+	for _, node := range config.Nodes {
+		for _, c := range node.Choices {
+			if !choiceApproaches[c.Approach] {
+				return fmt.Errorf("node %s choice %q: unknown approach %q", node.ID, c.Label, c.Approach)
+			}
+		}
+	}
 	for _, node := range config.Nodes {
 		r.Nodes[node.ID] = node
 	}
@@ -143,6 +183,13 @@ func (r *BlueprintRegistry) LoadEnemies(path string) error {
 		return err
 	}
 
+	for _, enemy := range config.Enemies {
+		switch enemy.ArmorType {
+		case "", "KINETIC", "ENERGY", "EXPLOSIVE", "VOID":
+		default:
+			return fmt.Errorf("enemy %s: unknown armor_type %q", enemy.ID, enemy.ArmorType)
+		}
+	}
 	for _, enemy := range config.Enemies {
 		r.Enemies[enemy.ID] = enemy
 	}
@@ -150,3 +197,69 @@ func (r *BlueprintRegistry) LoadEnemies(path string) error {
 	fmt.Printf("Loaded %d enemy blueprints from %s\n", len(config.Enemies), path)
 	return nil
 }
+
+// ValidateGraph checks the expedition's node graph, starting from its first node: every next_nodes
+// reference must exist, every node must be reachable, there must be no cycles, and every path must
+// finish on an is_end node.
+func (e ExpeditionBlueprint) ValidateGraph() error {
+	if len(e.Nodes) == 0 {
+		return fmt.Errorf("has no nodes")
+	}
+
+	next := make(map[string][]string, len(e.Nodes))
+	for _, n := range e.Nodes {
+		if n.ID == "" {
+			return fmt.Errorf("node without an id")
+		}
+		if _, dup := next[n.ID]; dup {
+			return fmt.Errorf("duplicate node id %q", n.ID)
+		}
+		next[n.ID] = n.NextNodes
+	}
+	for _, n := range e.Nodes {
+		if n.IsEnd && len(n.NextNodes) > 0 {
+			return fmt.Errorf("end node %q has next_nodes", n.ID)
+		}
+		if !n.IsEnd && len(n.NextNodes) == 0 {
+			return fmt.Errorf("node %q is a dead end (no next_nodes and not is_end)", n.ID)
+		}
+		for _, to := range n.NextNodes {
+			if _, ok := next[to]; !ok {
+				return fmt.Errorf("node %q points to unknown node %q", n.ID, to)
+			}
+		}
+	}
+
+	// Depth-first walk from the start: grey nodes are on the current path, so meeting one again is a cycle
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make(map[string]int, len(e.Nodes))
+	var visit func(id string) error
+	visit = func(id string) error {
+		color[id] = grey
+		for _, to := range next[id] {
+			switch color[to] {
+			case grey:
+				return fmt.Errorf("cycle through %q -> %q", id, to)
+			case white:
+				if err := visit(to); err != nil {
+					return err
+				}
+			}
+		}
+		color[id] = black
+		return nil
+	}
+	if err := visit(e.Nodes[0].ID); err != nil {
+		return err
+	}
+	for _, n := range e.Nodes {
+		if color[n.ID] == white {
+			return fmt.Errorf("node %q is unreachable from %q", n.ID, e.Nodes[0].ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HazardBlueprint defines an environmental hazard and everything it does to a node or fight.
+// Additive modifiers default to 0 and multipliers to 1 when omitted.
+type HazardBlueprint struct {
+	ID                 string   `yaml:"id"` // Matches the node's hazard (e.g. EMP_STORM)
+	Name               string   `yaml:"name"`
+	Description        string   `yaml:"description"`
+	Terrains           []string `yaml:"terrains"`            // Terrains the generator may roll it on; empty = any
+	RequiresAtmosphere bool     `yaml:"requires_atmosphere"` // Only rolled on atmospheric sites
+	VisualPrompt       string   `yaml:"visual_prompt"`       // Appended to encounter image prompts
+
+	// Node resolution
+	SuccessChance    float64 `yaml:"success_chance"`    // Added to every choice's success chance
+	Stress           int     `yaml:"stress"`            // Extra pilot stress per resolution
+	FuelDrain        float64 `yaml:"fuel_drain"`        // Fuel cost multiplier
+	O2Drain          float64 `yaml:"o2_drain"`          // O2 cost multiplier
+	DurabilityDamage int     `yaml:"durability_damage"` // Damage to the expedition vehicle per resolution
+
+	// Combat
+	EnemyScale float64 `yaml:"enemy_scale"` // Multiplies spawned enemy stats
+	Combat     struct {
+		DisabledDamageTypes []string           `yaml:"disabled_damage_types"` // Weapons and attacks of these types cannot fire
+		DamageMultipliers   map[string]float64 `yaml:"damage_multipliers"`    // Damage type -> multiplier, both sides
+	} `yaml:"combat"`
+
+	Counters []HazardCounter `yaml:"counters"`
+}
+
+// HazardCounter is research or an equipped item that reduces a hazard. Reductions add up;
+// at 1 or more the hazard has no effect at all.
+type HazardCounter struct {
+	Research  string  `yaml:"research,omitempty"` // metadata.unlocked_research ID
+	Item      string  `yaml:"item,omitempty"`     // Equipped item name
+	Reduction float64 `yaml:"reduction"`
+}
+
+// HazardEffect is a hazard's modifiers after counters, ready to apply
+type HazardEffect struct {
+	HazardID            string             `json:"hazard_id"`
+	Name                string             `json:"name"`
+	Mitigation          float64            `json:"mitigation"` // Share of the hazard removed by counters
+	SuccessChance       float64            `json:"success_chance"`
+	Stress              int                `json:"stress"`
+	FuelDrain           float64            `json:"fuel_drain"`
+	O2Drain             float64            `json:"o2_drain"`
+	DurabilityDamage    int                `json:"durability_damage"`
+	EnemyScale          float64            `json:"enemy_scale"`
+	DisabledDamageTypes []string           `json:"disabled_damage_types,omitempty"`
+	DamageMultipliers   map[string]float64 `json:"damage_multipliers,omitempty"`
+	VisualPrompt        string             `json:"visual_prompt,omitempty"`
+}
+
+func (r *BlueprintRegistry) LoadHazards(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var config struct {
+		Hazards []HazardBlueprint `yaml:"hazards"`
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	for _, h := range config.Hazards {
+		if err := h.validate(); err != nil {
+			return fmt.Errorf("hazard %s: %w", h.ID, err)
+		}
+	}
+	for _, h := range config.Hazards {
+		r.Hazards[h.ID] = h
+	}
+	return nil
+}
+
+func (h HazardBlueprint) validate() error {
+	if h.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if h.FuelDrain < 0 || h.O2Drain < 0 || h.EnemyScale < 0 || h.DurabilityDamage < 0 {
+		return fmt.Errorf("drains, enemy_scale and durability_damage must not be negative")
+	}
+	for _, t := range h.Combat.DisabledDamageTypes {
+		if !damageTypes[t] {
+			return fmt.Errorf("unknown disabled damage type %q", t)
+		}
+		if t == "KINETIC" {
+			return fmt.Errorf("KINETIC cannot be disabled: it is the unarmed fallback")
+		}
+	}
+	for t, m := range h.Combat.DamageMultipliers {
+		if !damageTypes[t] {
+			return fmt.Errorf("unknown damage multiplier type %q", t)
+		}
+		if m < 0 {
+			return fmt.Errorf("damage multiplier for %s must not be negative", t)
+		}
+	}
+	for i, c := range h.Counters {
+		if (c.Research == "") == (c.Item == "") {
+			return fmt.Errorf("counter %d needs exactly one of research or item", i)
+		}
+		if c.Research != "" {
+			if _, ok := ResearchCosts[c.Research]; !ok && MatrixNodeByID(c.Research) == nil {
+				return fmt.Errorf("counter %d: unknown research %q", i, c.Research)
+			}
+		}
+		if c.Reduction <= 0 || c.Reduction > 1 {
+			return fmt.Errorf("counter %d reduction must be in (0, 1]", i)
+		}
+	}
+	return nil
+}
+
+// HazardIDs returns the loaded hazard IDs in sorted order, so seeded draws do not depend on map order
+func (r *BlueprintRegistry) HazardIDs() []string {
+	ids := make([]string, 0, len(r.Hazards))
+	for id := range r.Hazards {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Mitigation sums the reductions of the counters the pilot has researched or has equipped (by item name), capped at 1
+func (h HazardBlueprint) Mitigation(pilot *PilotStats, equippedItems []string) float64 {
+	equipped := make(map[string]bool, len(equippedItems))
+	for _, name := range equippedItems {
+		equipped[name] = true
+	}
+	total := 0.0
+	for _, c := range h.Counters {
+		if (c.Research != "" && pilot != nil && pilot.HasUnlocked(c.Research)) || (c.Item != "" && equipped[c.Item]) {
+			total += c.Reduction
+		}
+	}
+	if total > 1 {
+		total = 1
+	}
+	return total
+}
+
+// Effect scales the hazard's modifiers by what its counters leave (1 - mitigation). A fully countered
+// hazard is neutral; otherwise disabled damage types stay disabled.
+func (h HazardBlueprint) Effect(mitigation float64) *HazardEffect {
+	left := 1 - mitigation
+	mult := func(v float64) float64 {
+		if v == 0 {
+			v = 1
+		}
+		return 1 + (v-1)*left
+	}
+
+	effect := &HazardEffect{
+		HazardID:         h.ID,
+		Name:             h.Name,
+		Mitigation:       mitigation,
+		SuccessChance:    h.SuccessChance * left,
+		Stress:           int(float64(h.Stress) * left),
+		FuelDrain:        mult(h.FuelDrain),
+		O2Drain:          mult(h.O2Drain),
+		DurabilityDamage: int(float64(h.DurabilityDamage) * left),
+		EnemyScale:       mult(h.EnemyScale),
+		VisualPrompt:     h.VisualPrompt,
+	}
+	if left > 0 {
+		effect.DisabledDamageTypes = h.Combat.DisabledDamageTypes
+		if len(h.Combat.DamageMultipliers) > 0 {
+			effect.DamageMultipliers = make(map[string]float64, len(h.Combat.DamageMultipliers))
+			for t, m := range h.Combat.DamageMultipliers {
+				effect.DamageMultipliers[t] = mult(m)
+			}
+		}
+	}
+	return effect
+}
@@ -0,0 +1,342 @@
+package game
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/ryudokung/Project-0/backend/internal/vehicle"
+	"gopkg.in/yaml.v3"
+)
+
+// Currencies a loot entry can grant into pilot_stats
+const (
+	CurrencyScrapMetal   = "SCRAP_METAL"
+	CurrencyResearchData = "RESEARCH_DATA"
+	CurrencyXP           = "XP"
+)
+
+// rarityStatScale multiplies an item template's stats by the rolled rarity
+var rarityStatScale = map[vehicle.RarityTier]float64{
+	vehicle.RarityCommon:      1.0,
+	vehicle.RarityRefined:     1.1,
+	vehicle.RarityRare:        1.25,
+	vehicle.RarityPrototype:   1.4,
+	vehicle.RarityLegendary:   1.6,
+	vehicle.RarityRelic:       2.0,
+	vehicle.RaritySingularity: 2.5,
+}
+
+// LootEntry is one outcome of a loot roll: a currency amount, an item template, or nothing when both are empty
+type LootEntry struct {
+	Weight   int    `yaml:"weight"` // Ignored for guaranteed drops
+	Currency string `yaml:"currency,omitempty"`
+	Min      int    `yaml:"min,omitempty"`
+	Max      int    `yaml:"max,omitempty"`  // Defaults to Min
+	Item     string `yaml:"item,omitempty"` // ItemTemplate ID
+}
+
+// LootTable drops every Guaranteed entry, then picks Rolls weighted entries
+type LootTable struct {
+	ID         string      `yaml:"id"`
+	Rolls      int         `yaml:"rolls"`
+	Guaranteed []LootEntry `yaml:"guaranteed"`
+	Entries    []LootEntry `yaml:"entries"`
+}
+
+// ItemTemplate describes an item a loot table can drop; its rarity is rolled from RarityWeights
+type ItemTemplate struct {
+	ID            string         `yaml:"id"`
+	Name          string         `yaml:"name"`
+	ItemType      string         `yaml:"item_type"`
+	Slot          string         `yaml:"slot,omitempty"`
+	DamageType    string         `yaml:"damage_type,omitempty"`
+	Tier          int            `yaml:"tier"`
+	MaxDurability int            `yaml:"max_durability"`
+	RarityWeights map[string]int `yaml:"rarity_weights"`
+	Stats         struct {
+		HP           int `yaml:"hp"`
+		Attack       int `yaml:"attack"`
+		Defense      int `yaml:"defense"`
+		Speed        int `yaml:"speed"`
+		BonusHP      int `yaml:"bonus_hp"`
+		BonusAttack  int `yaml:"bonus_attack"`
+		BonusDefense int `yaml:"bonus_defense"`
+	} `yaml:"stats"`
+}
+
+// LootItem is an item rolled from a template, not yet in anyone's inventory
+type LootItem struct {
+	TemplateID string             `json:"template_id"`
+	Name       string             `json:"name"`
+	ItemType   vehicle.ItemType   `json:"item_type"`
+	Rarity     vehicle.RarityTier `json:"rarity"`
+	Tier       int                `json:"tier"`
+	Slot       string             `json:"slot,omitempty"`
+	DamageType string             `json:"damage_type,omitempty"`
+	Durability int                `json:"durability"`
+	Stats      vehicle.ItemStats  `json:"stats"`
+}
+
+// LootDrop is the result of rolling a loot table
+type LootDrop struct {
+	TableID    string         `json:"table_id"`
+	Seed       int64          `json:"seed"`
+	Currencies map[string]int `json:"currencies,omitempty"`
+	Items      []LootItem     `json:"items,omitempty"`
+}
+
+// LootSeed derives a stable roll seed from identifiers (e.g. an enemy ID, or a node ID and choice label)
+func LootSeed(parts ...string) int64 {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return int64(h.Sum64())
+}
+
+func (r *BlueprintRegistry) LoadLoot(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var config struct {
+		ItemTemplates []ItemTemplate `yaml:"item_templates"`
+		LootTables    []LootTable    `yaml:"loot_tables"`
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	templates := make(map[string]ItemTemplate, len(config.ItemTemplates))
+	for _, t := range config.ItemTemplates {
+		if err := t.validate(); err != nil {
+			return fmt.Errorf("item template %s: %w", t.ID, err)
+		}
+		templates[t.ID] = t
+	}
+	for _, table := range config.LootTables {
+		if table.Rolls < 0 {
+			return fmt.Errorf("loot table %s: rolls must not be negative", table.ID)
+		}
+		for i, e := range append(append([]LootEntry{}, table.Guaranteed...), table.Entries...) {
+			if err := e.validate(templates); err != nil {
+				return fmt.Errorf("loot table %s entry %d: %w", table.ID, i, err)
+			}
+		}
+	}
+
+	for id, t := range templates {
+		r.ItemTemplates[id] = t
+	}
+	for _, table := range config.LootTables {
+		r.LootTables[table.ID] = table
+	}
+
+	fmt.Printf("Loaded %d loot tables and %d item templates from %s\n", len(config.LootTables), len(config.ItemTemplates), path)
+	return nil
+}
+
+func (t ItemTemplate) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch vehicle.ItemType(t.ItemType) {
+	case vehicle.ItemTypePart, vehicle.ItemTypeConsumable, vehicle.ItemTypeExosuit:
+	default:
+		return fmt.Errorf("unsupported item_type %q", t.ItemType)
+	}
+	for rarity, w := range t.RarityWeights {
+		if _, ok := rarityStatScale[vehicle.RarityTier(rarity)]; !ok {
+			return fmt.Errorf("unknown rarity %q", rarity)
+		}
+		if w < 0 {
+			return fmt.Errorf("rarity %s has a negative weight", rarity)
+		}
+	}
+	return nil
+}
+
+func (e LootEntry) validate(templates map[string]ItemTemplate) error {
+	if e.Weight < 0 {
+		return fmt.Errorf("weight must not be negative")
+	}
+	if e.Currency != "" && e.Item != "" {
+		return fmt.Errorf("an entry grants either a currency or an item, not both")
+	}
+	if e.Currency != "" {
+		switch e.Currency {
+		case CurrencyScrapMetal, CurrencyResearchData, CurrencyXP:
+		default:
+			return fmt.Errorf("unknown currency %q", e.Currency)
+		}
+		if e.Min < 0 || (e.Max != 0 && e.Max < e.Min) {
+			return fmt.Errorf("invalid amount range %d-%d", e.Min, e.Max)
+		}
+	}
+	if e.Item != "" {
+		if _, ok := templates[e.Item]; !ok {
+			return fmt.Errorf("unknown item template %q", e.Item)
+		}
+	}
+	return nil
+}
+
+// RollLoot rolls a loot table `times` times (once per defeated enemy) from seed; the same seed always yields the same drop
+func (r *BlueprintRegistry) RollLoot(tableID string, seed int64, times int) (*LootDrop, error) {
+	table, ok := r.LootTables[tableID]
+	if !ok {
+		return nil, fmt.Errorf("loot table %s not found", tableID)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	drop := &LootDrop{TableID: tableID, Seed: seed, Currencies: map[string]int{}}
+	if times < 1 {
+		times = 1
+	}
+	for n := 0; n < times; n++ {
+		for _, e := range table.Guaranteed {
+			r.grantEntry(drop, e, rng)
+		}
+		for i := 0; i < table.Rolls; i++ {
+			if e := pickEntry(table.Entries, rng); e != nil {
+				r.grantEntry(drop, *e, rng)
+			}
+		}
+	}
+	return drop, nil
+}
+
+func pickEntry(entries []LootEntry, rng *rand.Rand) *LootEntry {
+	total := 0
+	for _, e := range entries {
+		total += e.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	roll := rng.Intn(total)
+	for i := range entries {
+		roll -= entries[i].Weight
+		if roll < 0 {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+func (r *BlueprintRegistry) grantEntry(drop *LootDrop, e LootEntry, rng *rand.Rand) {
+	if e.Currency != "" {
+		amount := e.Min
+		if e.Max > e.Min {
+			amount += rng.Intn(e.Max - e.Min + 1)
+		}
+		drop.Currencies[e.Currency] += amount
+	}
+	if e.Item != "" {
+		if t, ok := r.ItemTemplates[e.Item]; ok {
+			drop.Items = append(drop.Items, t.roll(rng))
+		}
+	}
+}
+
+// roll picks the rarity (sorted for determinism) and scales the template's stats by it
+func (t ItemTemplate) roll(rng *rand.Rand) LootItem {
+	rarity := vehicle.RarityCommon
+	rarities := make([]string, 0, len(t.RarityWeights))
+	total := 0
+	for r, w := range t.RarityWeights {
+		rarities = append(rarities, r)
+		total += w
+	}
+	sort.Strings(rarities)
+	if total > 0 {
+		roll := rng.Intn(total)
+		for _, r := range rarities {
+			roll -= t.RarityWeights[r]
+			if roll < 0 {
+				rarity = vehicle.RarityTier(r)
+				break
+			}
+		}
+	}
+
+	scale := rarityStatScale[rarity]
+	scaled := func(v int) int {
+		return int(math.Round(float64(v) * scale))
+	}
+	durability := t.MaxDurability
+	if durability <= 0 {
+		durability = 1000
+	}
+	tier := t.Tier
+	if tier <= 0 {
+		tier = 1
+	}
+	return LootItem{
+		TemplateID: t.ID,
+		Name:       t.Name,
+		ItemType:   vehicle.ItemType(t.ItemType),
+		Rarity:     rarity,
+		Tier:       tier,
+		Slot:       t.Slot,
+		DamageType: t.DamageType,
+		Durability: durability,
+		Stats: vehicle.ItemStats{
+			HP:           scaled(t.Stats.HP),
+			Attack:       scaled(t.Stats.Attack),
+			Defense:      scaled(t.Stats.Defense),
+			Speed:        scaled(t.Stats.Speed),
+			BonusHP:      scaled(t.Stats.BonusHP),
+			BonusAttack:  scaled(t.Stats.BonusAttack),
+			BonusDefense: scaled(t.Stats.BonusDefense),
+		},
+	}
+}
+
+// ScaleCurrencies multiplies every currency amount (e.g. the Bastion lab bonus)
+func (d *LootDrop) ScaleCurrencies(mod float64) {
+	for c, amount := range d.Currencies {
+		d.Currencies[c] = int(float64(amount) * mod)
+	}
+}
+
+// ApplyCurrencies adds the drop's currencies to the pilot; the caller persists the stats
+func (d *LootDrop) ApplyCurrencies(p *PilotStats) {
+	p.ScrapMetal += d.Currencies[CurrencyScrapMetal]
+	p.ResearchData += d.Currencies[CurrencyResearchData]
+	p.XP += d.Currencies[CurrencyXP]
+}
+
+// NewItem turns a rolled item into an unequipped inventory item owned by ownerID
+func (i LootItem) NewItem(ownerID uuid.UUID, characterID *uuid.UUID) *vehicle.Item {
+	item := &vehicle.Item{
+		ID:            uuid.New(),
+		OwnerID:       ownerID,
+		CharacterID:   characterID,
+		Name:          i.Name,
+		ItemType:      i.ItemType,
+		Rarity:        i.Rarity,
+		Tier:          i.Tier,
+		Durability:    i.Durability,
+		MaxDurability: i.Durability,
+		Condition:     vehicle.ConditionPristine,
+		Stats:         i.Stats,
+		Metadata:      map[string]interface{}{"loot_template": i.TemplateID},
+	}
+	if i.Slot != "" {
+		slot := i.Slot
+		item.Slot = &slot
+	}
+	if i.DamageType != "" {
+		dt := i.DamageType
+		item.DamageType = &dt
+	}
+	return item
+}
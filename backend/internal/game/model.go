@@ -1,6 +1,7 @@
 package game
 
 import (
+	"strings"
 	"time"
 	"github.com/google/uuid"
 )
@@ -27,6 +28,30 @@ type PilotStats struct {
 	UpdatedAt         time.Time              `json:"updated_at"`
 }
 
+// HasUnlocked reports whether a research project or Engineering Matrix node is in metadata.unlocked_research
+func (p *PilotStats) HasUnlocked(id string) bool {
+	unlocked, _ := p.Metadata["unlocked_research"].([]interface{})
+	for _, u := range unlocked {
+		if u == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Attribute returns a character attribute by name, ignoring case (0 when unset)
+func (p *PilotStats) Attribute(name string) int {
+	if v, ok := p.CharacterAttributes[name]; ok {
+		return v
+	}
+	for k, v := range p.CharacterAttributes {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return 0
+}
+
 type GachaStats struct {
 	UserID               uuid.UUID `json:"user_id"`
 	PityRelicCount       int       `json:"pity_relic_count"`
@@ -47,7 +72,25 @@ type BastionModule struct {
 }
 
 type ScriptEvent struct {
-	Trigger  string `json:"trigger" yaml:"trigger"`
-	Action   string `json:"action" yaml:"action"`
-	Dialogue string `json:"dialogue" yaml:"dialogue"`
+	Trigger   string       `json:"trigger" yaml:"trigger"`
+	Threshold float64      `json:"threshold,omitempty" yaml:"threshold,omitempty"` // HP percent for *_hp_below, turn number for turn
+	Action    string       `json:"action" yaml:"action"`
+	Params    ScriptParams `json:"params,omitempty" yaml:"params,omitempty"`
+	Dialogue  string       `json:"dialogue" yaml:"dialogue"`
+	Repeat    bool         `json:"repeat,omitempty" yaml:"repeat,omitempty"` // Fire again each time the trigger re-occurs instead of once
+}
+
+// ScriptParams holds the arguments of a script action; each action reads only the fields it needs
+type ScriptParams struct {
+	Target     string  `json:"target,omitempty" yaml:"target,omitempty"` // player | enemy
+	Count      int     `json:"count,omitempty" yaml:"count,omitempty"`
+	HP         int     `json:"hp,omitempty" yaml:"hp,omitempty"`
+	Attack     int     `json:"attack,omitempty" yaml:"attack,omitempty"`
+	Defense    int     `json:"defense,omitempty" yaml:"defense,omitempty"`
+	Amount     int     `json:"amount,omitempty" yaml:"amount,omitempty"`
+	Percent    float64 `json:"percent,omitempty" yaml:"percent,omitempty"` // Heal as % of MaxHP
+	DamageType string  `json:"damage_type,omitempty" yaml:"damage_type,omitempty"`
+	Stat       string  `json:"stat,omitempty" yaml:"stat,omitempty"`         // attack | defense | speed | evasion
+	Modifier   float64 `json:"modifier,omitempty" yaml:"modifier,omitempty"` // Multiplier delta, e.g. 0.5 = +50%
+	Duration   int     `json:"duration,omitempty" yaml:"duration,omitempty"` // Turns
 }
@@ -83,3 +83,13 @@ var EngineeringMatrix = []MatrixNode{
 		Cost:        1000,
 	},
 }
+
+// MatrixNodeByID returns the Engineering Matrix node with the given ID, or nil
+func MatrixNodeByID(id string) *MatrixNode {
+	for i := range EngineeringMatrix {
+		if EngineeringMatrix[i].ID == id {
+			return &EngineeringMatrix[i]
+		}
+	}
+	return nil
+}
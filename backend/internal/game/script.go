@@ -0,0 +1,102 @@
+package game
+
+import "fmt"
+
+// Script triggers
+const (
+	TriggerPlayerHPBelow      = "player_hp_below"     // Player HP under Threshold percent
+	TriggerEnemyHPBelow       = "enemy_hp_below"      // Enemy HP under Threshold percent
+	TriggerEnemyDefeated      = "enemy_defeated"      // Enemy HP reached zero
+	TriggerTurn               = "turn"                // Turn Threshold reached (every Threshold turns when Repeat)
+	TriggerResonanceActivated = "resonance_activated" // Player entered Resonance Mode
+	TriggerPlayerHPLow        = "player_hp_low"       // Legacy: player_hp_below 25
+	TriggerBossPhase2         = "boss_phase_2"        // Legacy: enemy mech destroyed
+)
+
+// Script actions
+const (
+	ActionForceEject       = "force_eject"
+	ActionSpawnHumanPilot  = "spawn_human_pilot"
+	ActionSpawnAdds        = "spawn_adds"
+	ActionHeal             = "heal"
+	ActionChangeDamageType = "change_damage_type"
+	ActionBuff             = "buff"
+	ActionDialogue         = "dialogue"
+)
+
+// Script targets
+const (
+	ScriptTargetPlayer = "player"
+	ScriptTargetEnemy  = "enemy"
+)
+
+var scriptTriggers = map[string]bool{
+	TriggerPlayerHPBelow: true, TriggerEnemyHPBelow: true, TriggerEnemyDefeated: true, TriggerTurn: true,
+	TriggerResonanceActivated: true, TriggerPlayerHPLow: true, TriggerBossPhase2: true,
+}
+
+var scriptActions = map[string]bool{
+	ActionForceEject: true, ActionSpawnHumanPilot: true, ActionSpawnAdds: true, ActionHeal: true,
+	ActionChangeDamageType: true, ActionBuff: true, ActionDialogue: true,
+}
+
+// damageTypes are the combat damage types blueprints may name
+var damageTypes = map[string]bool{"KINETIC": true, "ENERGY": true, "EXPLOSIVE": true, "VOID": true}
+
+var scriptBuffStats = map[string]bool{"attack": true, "defense": true, "speed": true, "evasion": true}
+
+// Validate checks that a script event only uses known triggers and actions with usable parameters
+func (e ScriptEvent) Validate() error {
+	if !scriptTriggers[e.Trigger] {
+		return fmt.Errorf("unknown trigger %q", e.Trigger)
+	}
+	if !scriptActions[e.Action] {
+		return fmt.Errorf("unknown action %q", e.Action)
+	}
+
+	switch e.Trigger {
+	case TriggerPlayerHPBelow, TriggerEnemyHPBelow:
+		if e.Threshold <= 0 || e.Threshold > 100 {
+			return fmt.Errorf("trigger %s needs a threshold between 0 and 100, got %v", e.Trigger, e.Threshold)
+		}
+	case TriggerTurn:
+		if e.Threshold < 1 || e.Threshold != float64(int(e.Threshold)) {
+			return fmt.Errorf("trigger %s needs a whole turn number >= 1, got %v", e.Trigger, e.Threshold)
+		}
+	}
+
+	p := e.Params
+	if p.Target != "" && p.Target != ScriptTargetPlayer && p.Target != ScriptTargetEnemy {
+		return fmt.Errorf("unknown target %q", p.Target)
+	}
+
+	switch e.Action {
+	case ActionSpawnAdds:
+		if p.Count < 0 || p.HP < 0 || p.Attack < 0 || p.Defense < 0 {
+			return fmt.Errorf("spawn_adds parameters must not be negative")
+		}
+	case ActionHeal:
+		if p.Amount <= 0 && p.Percent <= 0 {
+			return fmt.Errorf("heal needs a positive amount or percent")
+		}
+	case ActionChangeDamageType:
+		if !damageTypes[p.DamageType] {
+			return fmt.Errorf("unknown damage_type %q", p.DamageType)
+		}
+	case ActionBuff:
+		if !scriptBuffStats[p.Stat] {
+			return fmt.Errorf("unknown buff stat %q", p.Stat)
+		}
+		if p.Modifier == 0 {
+			return fmt.Errorf("buff needs a non-zero modifier")
+		}
+		if p.Duration < 0 {
+			return fmt.Errorf("buff duration must not be negative")
+		}
+	case ActionDialogue:
+		if e.Dialogue == "" {
+			return fmt.Errorf("dialogue action needs dialogue text")
+		}
+	}
+	return nil
+}
@@ -68,38 +68,8 @@ func (u *gameUseCase) InitializeNewCharacter(userID, charID uuid.UUID) error {
 	}
 
 	// 4. Assign Starter Items (Modules)
-	starterItems := []struct {
-		name string
-		slot string
-		atk  int
-		def  int
-	}{
-		{"Starter Kinetic Arm", "ARM_R", 5, 0},
-		{"Starter Plating", "CORE", 0, 5},
-	}
-
-	for _, si := range starterItems {
-		slot := si.slot
-		item := vehicle.Item{
-			ID:            uuid.New(),
-			OwnerID:       userID,
-			CharacterID:   &charID,
-			Name:          si.name,
-			ItemType:      vehicle.ItemTypePart,
-			Rarity:        vehicle.RarityCommon,
-			Tier:          1,
-			Slot:          &slot,
-			Durability:    100,
-			MaxDurability: 100,
-			Condition:     vehicle.ConditionPristine,
-			Stats: vehicle.ItemStats{
-				BonusAttack:  si.atk,
-				BonusDefense: si.def,
-			},
-			IsEquipped:   true,
-			ParentItemID: &starterShip.ID,
-		}
-		_ = u.vehicleRepo.CreateItem(context.Background(), &item)
+	for _, si := range vehicle.StarterItems {
+		_ = u.vehicleRepo.CreateItem(context.Background(), si.NewItem(userID, &charID, starterShip.ID, 100))
 	}
 
 	return nil
@@ -117,7 +87,12 @@ func (uc *gameUseCase) UnlockResearch(ctx context.Context, userID uuid.UUID, res
 
 	cost, ok := ResearchCosts[researchID]
 	if !ok {
-		return nil, errors.New("invalid research ID")
+		// Engineering Matrix nodes are researched the same way
+		node := MatrixNodeByID(researchID)
+		if node == nil {
+			return nil, errors.New("invalid research ID")
+		}
+		cost = node.Cost
 	}
 
 	// Check if already unlocked
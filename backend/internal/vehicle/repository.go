@@ -29,6 +29,7 @@ type Repository interface {
 	CreateItem(ctx context.Context, item *Item) error
 	GetItemByID(ctx context.Context, id uuid.UUID) (*Item, error)
 	UpdateItem(ctx context.Context, item *Item) error
+	UpdateItems(ctx context.Context, items []Item) error // All or nothing, in one transaction
 	GetItemsByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]Item, error)
 	GetItemsByParentItemID(ctx context.Context, parentItemID uuid.UUID) ([]Item, error)
 	UpdateDurability(ctx context.Context, id uuid.UUID, durability int, condition ItemCondition) error
@@ -335,6 +336,30 @@ func (r *vehicleRepository) GetItemByID(ctx context.Context, id uuid.UUID) (*Ite
 }
 
 func (r *vehicleRepository) UpdateItem(ctx context.Context, i *Item) error {
+	return updateItem(ctx, r.db, i)
+}
+
+func (r *vehicleRepository) UpdateItems(ctx context.Context, items []Item) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range items {
+		if err := updateItem(ctx, tx, &items[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func updateItem(ctx context.Context, db execer, i *Item) error {
 	statsJSON, _ := json.Marshal(i.Stats)
 	dnaJSON, _ := json.Marshal(i.VisualDNA)
 	metaJSON, _ := json.Marshal(i.Metadata)
@@ -347,7 +372,7 @@ func (r *vehicleRepository) UpdateItem(ctx context.Context, i *Item) error {
 			metadata = $17, is_equipped = $18, parent_item_id = $19
 		WHERE id = $20
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		i.OwnerID, i.CharacterID, i.Name, i.ItemType, i.Rarity,
 		i.Tier, i.Slot, i.DamageType, i.SeriesID, i.IsNFT, i.TokenID, i.Durability,
 		i.MaxDurability, i.Condition, statsJSON, dnaJSON,
@@ -17,9 +17,11 @@ type UseCase interface {
 
 	// Item operations (DDS)
 	ApplyDamage(ctx context.Context, itemID uuid.UUID, damage int) (*Item, error)
+	ApplyCombatDamage(ctx context.Context, vehicleID uuid.UUID, damage int) ([]Item, error)
 	RepairItem(ctx context.Context, itemID uuid.UUID, amount int) (*Item, error)
 	GetItems(ctx context.Context, userID uuid.UUID) ([]Item, error)
 	GetItemByID(ctx context.Context, itemID uuid.UUID) (*Item, error)
+	CreateItem(ctx context.Context, item *Item) error
 	GetVehicleCP(ctx context.Context, vehicleID uuid.UUID) (int, error)
 	EquipItem(ctx context.Context, itemID uuid.UUID, vehicleID uuid.UUID) error
 	UnequipItem(ctx context.Context, itemID uuid.UUID) error
@@ -37,6 +39,51 @@ func NewUseCase(repo Repository) UseCase {
 	return &vehicleUseCase{repo: repo}
 }
 
+// StarterItem is a part fitted, equipped, to every starter vehicle
+type StarterItem struct {
+	Name       string
+	Slot       string
+	Attack     int
+	Defense    int
+	DamageType string // Non-empty makes the part a weapon
+}
+
+// StarterItems are the parts of starter vehicles, shared by the starter pack and new-pilot onboarding
+var StarterItems = []StarterItem{
+	{Name: "Starter Kinetic Arm", Slot: "ARM_R", Attack: 5, DamageType: "KINETIC"},
+	{Name: "Starter Plating", Slot: "CORE", Defense: 5},
+}
+
+// NewItem builds the part equipped on vehicleID
+func (si StarterItem) NewItem(ownerID uuid.UUID, characterID *uuid.UUID, vehicleID uuid.UUID, durability int) *Item {
+	slot := si.Slot
+	var damageType *string
+	if si.DamageType != "" {
+		dt := si.DamageType
+		damageType = &dt
+	}
+	return &Item{
+		ID:            uuid.New(),
+		OwnerID:       ownerID,
+		CharacterID:   characterID,
+		Name:          si.Name,
+		ItemType:      ItemTypePart,
+		Rarity:        RarityCommon,
+		Tier:          1,
+		Slot:          &slot,
+		DamageType:    damageType,
+		Durability:    durability,
+		MaxDurability: durability,
+		Condition:     ConditionPristine,
+		Stats: ItemStats{
+			BonusAttack:  si.Attack,
+			BonusDefense: si.Defense,
+		},
+		IsEquipped:   true,
+		ParentItemID: &vehicleID,
+	}
+}
+
 func (u *vehicleUseCase) InitializeStarterPack(ctx context.Context, userID uuid.UUID) (*Vehicle, error) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -86,37 +133,8 @@ func (u *vehicleUseCase) InitializeStarterPack(ctx context.Context, userID uuid.
 	}
 
 	// Create Starter Items
-	starterItems := []struct {
-		name string
-		slot string
-		atk  int
-		def  int
-	}{
-		{"Starter Kinetic Arm", "ARM_R", 5, 0},
-		{"Starter Plating", "CORE", 0, 5},
-	}
-
-	for _, si := range starterItems {
-		slot := si.slot
-		item := &Item{
-			ID:            uuid.New(),
-			OwnerID:       userID,
-			Name:          si.name,
-			ItemType:      ItemTypePart,
-			Rarity:        RarityCommon,
-			Tier:          1,
-			Slot:          &slot,
-			Durability:    1000,
-			MaxDurability: 1000,
-			Condition:     ConditionPristine,
-			Stats: ItemStats{
-				BonusAttack:  si.atk,
-				BonusDefense: si.def,
-			},
-			IsEquipped:   true,
-			ParentItemID: &v.ID,
-		}
-		_ = u.repo.CreateItem(ctx, item)
+	for _, si := range StarterItems {
+		_ = u.repo.CreateItem(ctx, si.NewItem(userID, nil, v.ID, 1000))
 	}
 
 	return v, nil
@@ -153,6 +171,10 @@ func (u *vehicleUseCase) GetItemByID(ctx context.Context, itemID uuid.UUID) (*It
 	return u.repo.GetItemByID(ctx, itemID)
 }
 
+func (u *vehicleUseCase) CreateItem(ctx context.Context, item *Item) error {
+	return u.repo.CreateItem(ctx, item)
+}
+
 func (u *vehicleUseCase) GetVehicleCP(ctx context.Context, vehicleID uuid.UUID) (int, error) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -311,6 +333,75 @@ func (u *vehicleUseCase) ApplyDamage(ctx context.Context, itemID uuid.UUID, dama
 		return nil, err
 	}
 
+	damageItem(item, damage)
+
+	if err := u.repo.UpdateItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// combatVehicleShare is the part of battle wear taken by the vehicle's own item; equipped parts split the rest
+const combatVehicleShare = 0.5
+
+// ApplyCombatDamage spreads durability damage from a battle across the vehicle item and its equipped parts
+func (u *vehicleUseCase) ApplyCombatDamage(ctx context.Context, vehicleID uuid.UUID, damage int) ([]Item, error) {
+	if damage <= 0 {
+		return nil, nil
+	}
+
+	vehicleItem, err := u.repo.GetItemByID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := u.repo.GetItemsByParentItemID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	var parts []*Item
+	for i := range children {
+		if children[i].IsEquipped {
+			parts = append(parts, &children[i])
+		}
+	}
+
+	// Split the damage: the vehicle takes its share, parts divide the rest evenly
+	vehicleDamage := damage
+	if len(parts) > 0 {
+		vehicleDamage = int(float64(damage) * combatVehicleShare)
+		if vehicleItem == nil {
+			vehicleDamage = 0
+		}
+	}
+	partsDamage := damage - vehicleDamage
+
+	var updated []Item
+	if vehicleItem != nil && vehicleDamage > 0 {
+		damageItem(vehicleItem, vehicleDamage)
+		updated = append(updated, *vehicleItem)
+	}
+	for i, part := range parts {
+		share := partsDamage / len(parts)
+		if i < partsDamage%len(parts) {
+			share++
+		}
+		if share == 0 {
+			continue
+		}
+		damageItem(part, share)
+		updated = append(updated, *part)
+	}
+
+	// One transaction, so a failed write never leaves the wear half-applied
+	if err := u.repo.UpdateItems(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// damageItem lowers durability and refreshes condition and visuals
+func damageItem(item *Item, damage int) {
 	item.Durability -= damage
 	if item.Durability < 0 {
 		item.Durability = 0
@@ -320,12 +411,6 @@ func (u *vehicleUseCase) ApplyDamage(ctx context.Context, itemID uuid.UUID, dama
 	
 	// Update Visual DNA based on condition
 	updateVisualsByCondition(item)
-
-	if err := u.repo.UpdateItem(ctx, item); err != nil {
-		return nil, err
-	}
-
-	return item, nil
 }
 
 func (u *vehicleUseCase) RepairItem(ctx context.Context, itemID uuid.UUID, amount int) (*Item, error) {
@@ -4,6 +4,12 @@ const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL
 
 export type DamageType = 'KINETIC' | 'ENERGY' | 'EXPLOSIVE';
 
+export interface Weapon {
+  item_id: string;
+  name: string;
+  damage_type: DamageType;
+}
+
 export interface UnitStats {
   hp: number;
   max_hp: number;
@@ -13,6 +19,7 @@ export interface UnitStats {
   accuracy: number;
   evasion: number;
   speed: number;
+  weapons?: Weapon[];
 }
 
 export interface CombatResult {
@@ -40,7 +47,8 @@ const getAuthHeaders = () => {
 };
 
 export const combatService = {
-  async simulateAttack(attackerVehicleId: string, defenderVehicleId: string, damageType: DamageType = 'KINETIC'): Promise<BattleResponse> {
+  // Armed attackers must name an equipped weapon; damageType only applies to unarmed attacks
+  async simulateAttack(attackerVehicleId: string, defenderVehicleId: string, damageType: DamageType = 'KINETIC', weaponId?: string): Promise<BattleResponse> {
     const response = await fetch(`${API_BASE_URL}/combat/attack`, {
       method: 'POST',
       headers: getAuthHeaders(),
@@ -48,6 +56,7 @@ export const combatService = {
         attacker_vehicle_id: attackerVehicleId,
         defender_vehicle_id: defenderVehicleId,
         damage_type: damageType,
+        ...(weaponId ? { weapon_id: weaponId } : {}),
       }),
     });
 
@@ -287,11 +287,11 @@ export const explorationService = {
     };
   },
 
-  async advanceTimeline(expeditionId: string, vehicleId: string): Promise<AdvanceTimelineResponse> {
+  async advanceTimeline(expeditionId: string, vehicleId: string, nextNodeId?: string): Promise<AdvanceTimelineResponse> {
     const response = await fetch(`${API_BASE_URL}/exploration/advance`, {
       method: 'POST',
       headers: getAuthHeaders(),
-      body: JSON.stringify({ expedition_id: expeditionId, vehicle_id: vehicleId }),
+      body: JSON.stringify({ expedition_id: expeditionId, vehicle_id: vehicleId, next_node_id: nextNodeId }),
     });
     if (!response.ok) {
       const errorText = await response.text();
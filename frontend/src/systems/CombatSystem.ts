@@ -46,7 +46,8 @@ class CombatSystem {
     gameEvents.emit(GAME_EVENTS.COMBAT_UPDATED, this.getState());
 
     try {
-      const data: BattleResponse = await combatService.simulateAttack(attackerId, enemyId, type);
+      const weapon = this.state.attackerStats?.weapons?.find(w => w.damage_type === type);
+      const data: BattleResponse = await combatService.simulateAttack(attackerId, enemyId, type, weapon?.item_id);
       
       this.state.attackerStats = data.attacker_stats;
       this.state.defenderStats = data.defender_stats;